@@ -7,23 +7,32 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	gonanoid "github.com/matoous/go-nanoid"
 	"github.com/slack-go/slack"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 const (
-	TeamId = "test_team_id"
+	TeamId    = "test_team_id"
+	BotUserId = "test_bot_user_id"
+	BotId     = "test_bot_id"
 )
 
 type User interface {
 	Page
 	HomeOpen(t *testing.T) Page
+	EphemeralMessages() Messages
+	SendMessage(t *testing.T, channel string, text string) *MessageView
+	SlashCommand(t *testing.T, command string, text string) *MessageView
+	GlobalShortcut(t *testing.T, callbackId string)
+	CloseModal(t *testing.T)
+	WaitViewUpdate(t *testing.T)
+	Mention(t *testing.T, channel string, text string) *MessageView
 }
 
 type Trigger interface {
@@ -45,12 +54,79 @@ func (m *messages) Last() *message {
 type message struct {
 	page
 	slackMessage *slack.Msg
+	ephemeral    bool
 	update       chan struct{}
+	deleted      chan struct{}
+}
+
+// delete tombstones the message: it stays reachable from existing views,
+// but is no longer listed in Messages.
+func (m *message) delete() {
+	if m.isDeleted() {
+		return
+	}
+
+	close(m.deleted)
+}
+
+func (m *message) isDeleted() bool {
+	select {
+	case <-m.deleted:
+		return true
+	default:
+		return false
+	}
+}
+
+func newMessage(slackMessage *slack.Msg) *message {
+	msg := message{
+		page: page{
+			state: map[string]map[string]slack.BlockAction{},
+		},
+		slackMessage: slackMessage,
+		update:       make(chan struct{}),
+		deleted:      make(chan struct{}),
+	}
+
+	msg.page.set(slackMessage.Blocks)
+
+	return &msg
 }
 
 type MessageView struct {
 	page
 	slackMessage *message
+	user         *userClient
+}
+
+// Thread returns replies to the message, oldest first.
+func (m *MessageView) Thread() Messages {
+	cv := m.user._client.conversation(m.slackMessage.slackMessage.Channel)
+	if cv == nil {
+		return nil
+	}
+
+	m.user._client.mu.Lock()
+	replies := cv.replies(m.slackMessage.slackMessage.Timestamp)
+	m.user._client.mu.Unlock()
+
+	return m.user.messageViews(replies)
+}
+
+// Reply posts a thread reply as the user and sends the message event to the app.
+func (m *MessageView) Reply(t *testing.T, text string) *MessageView {
+	cv := m.user._client.conversation(m.slackMessage.slackMessage.Channel)
+	if cv == nil {
+		t.Fatalf("cannot find conversation %s", m.slackMessage.slackMessage.Channel)
+		return nil
+	}
+
+	msg := m.user.postMessage(t, cv, text, m.slackMessage.slackMessage.Timestamp)
+	if msg == nil {
+		return nil
+	}
+
+	return m.user.messageViews([]*message{msg}).Last()
 }
 
 func (m *MessageView) WaitUpdate(t *testing.T) {
@@ -62,6 +138,62 @@ func (m *MessageView) WaitUpdate(t *testing.T) {
 	}
 }
 
+func (m *MessageView) IsDeleted() bool {
+	return m.slackMessage.isDeleted()
+}
+
+func (m *MessageView) WaitDeleted(t *testing.T) {
+	select {
+	case <-m.slackMessage.deleted:
+	case <-time.After(time.Second * 5):
+		t.Fatal("cannot wait delete for message")
+	}
+}
+
+// MessageShortcut triggers the message shortcut on this message and waits for the modal it opens.
+func (m *MessageView) MessageShortcut(t *testing.T, callbackId string) {
+	slackMessage := m.slackMessage.slackMessage
+
+	cv := m.user._client.conversation(slackMessage.Channel)
+	if cv == nil {
+		t.Fatalf("cannot find conversation %s", slackMessage.Channel)
+		return
+	}
+
+	triggerId := m.user.newTriggerId()
+
+	m.user._client.mu.Lock()
+	msg := cv.slackMessage(m.slackMessage)
+	m.user._client.mu.Unlock()
+
+	event := slack.InteractionCallback{
+		Type:        slack.InteractionTypeMessageAction,
+		CallbackID:  callbackId,
+		ResponseURL: "http://localhost" + m.user._client.port + "/api/response_url/" + slackMessage.Channel + "/" + slackMessage.Timestamp,
+		TriggerID:   triggerId,
+		ActionTs:    m.user._client.newTimestamp(),
+		Team: slack.Team{
+			ID: m.user.teamId,
+		},
+		Channel: slack.Channel{
+			GroupConversation: slack.GroupConversation{
+				Conversation: slack.Conversation{ID: cv.channel.ID},
+				Name:         cv.name(),
+			},
+		},
+		User:      *m.user.user,
+		Message:   msg,
+		MessageTs: slackMessage.Timestamp,
+	}
+
+	if _, err := m.user.client.SendInteractionAction(&event); err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	m.user.waitModal(t, triggerId, true, m.slackMessage)
+}
+
 type Messages []MessageView
 
 func (m Messages) Last() *MessageView {
@@ -79,37 +211,133 @@ type Client struct {
 	appClient *AppHttpClient
 
 	users           map[string]*slack.User
-	pagesByTriggers map[string]chan *slack.ModalViewRequest
-	viewsByTrigger  map[string]chan *slack.ModalViewRequest
-	messagesByUser  map[string]*messages
-	teamId          string
-	port            string
+	pagesByTriggers map[string]chan *view
+	triggers        map[string]*trigger
+	views           map[string]*view
+	homes           map[string]*view
+	conversations   map[string]*conversation
+	ephemeralByUser map[string]*messages
+	// filters of multi conversation selects by element, see keepFilters.
+	filters   map[*slack.MultiSelectBlockElement]*slack.SelectBlockElementFilter
+	teamId    string
+	botUserId string
+	port      string
+
+	mu            sync.Mutex
+	lastTimestamp int64
 }
 
-func NewClient(eventUrl string, interactionUrl string, signedSecret string, teamId string) *Client {
+func NewClient(eventUrl string, interactionUrl string, commandUrl string, signedSecret string, teamId string) *Client {
 	if teamId == "" {
 		teamId = TeamId
 	}
 
 	return &Client{
 		eventUrl:        eventUrl,
-		appClient:       NewAppHttpClient(eventUrl, interactionUrl, signedSecret, teamId),
+		appClient:       NewAppHttpClient(eventUrl, interactionUrl, commandUrl, signedSecret, teamId),
 		users:           map[string]*slack.User{},
-		pagesByTriggers: map[string]chan *slack.ModalViewRequest{},
-		viewsByTrigger:  make(map[string]chan *slack.ModalViewRequest, 1),
+		pagesByTriggers: map[string]chan *view{},
+		triggers:        map[string]*trigger{},
+		views:           map[string]*view{},
+		homes:           map[string]*view{},
 		teamId:          teamId,
-		messagesByUser:  map[string]*messages{},
+		botUserId:       BotUserId,
+		conversations:   map[string]*conversation{},
+		ephemeralByUser: map[string]*messages{},
+		filters:         map[*slack.MultiSelectBlockElement]*slack.SelectBlockElementFilter{},
 	}
 }
 
+// MessagesByUser returns top-level messages from every conversation the user is a member of, oldest first.
+// Thread replies are reachable through MessageView.Thread.
 func (c *Client) MessagesByUser(id string) *messages {
-	return c.messagesByUser[id]
+	userMessages := &messages{}
+
+	for _, cv := range c.userConversations(id) {
+		c.mu.Lock()
+		userMessages.List = append(userMessages.List, cv.history()...)
+		c.mu.Unlock()
+	}
+
+	sortMessages(userMessages.List)
+
+	return userMessages
+}
+
+// EphemeralMessagesByUser returns a copy of the messages posted with chat.postEphemeral to the user.
+func (c *Client) EphemeralMessagesByUser(id string) *messages {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	userMessages := &messages{}
+	if ephemeral := c.ephemeralByUser[id]; ephemeral != nil {
+		userMessages.List = append(userMessages.List, ephemeral.List...)
+	}
+
+	return userMessages
+}
+
+// postEphemeral stores a message visible only to the user.
+func (c *Client) postEphemeral(userId string, msg *slack.Msg) *message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if msg.Timestamp == "" {
+		msg.Timestamp = c.newTimestampLocked()
+	}
+
+	userMessages := c.ephemeralByUser[userId]
+	if userMessages == nil {
+		userMessages = &messages{}
+		c.ephemeralByUser[userId] = userMessages
+	}
+
+	m := newMessage(msg)
+	m.ephemeral = true
+	userMessages.List = append(userMessages.List, m)
+
+	return m
+}
+
+// findMessage looks up a message by channel and timestamp, including ephemeral
+// messages, which are only reachable through a response_url.
+func (c *Client) findMessage(channel string, ts string) *message {
+	cv := c.conversation(channel)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cv != nil {
+		if msg := cv.message(ts); msg != nil {
+			return msg
+		}
+	}
+
+	for _, userMessages := range c.ephemeralByUser {
+		for _, msg := range userMessages.List {
+			if msg.slackMessage.Channel == channel && msg.slackMessage.Timestamp == ts {
+				return msg
+			}
+		}
+	}
+
+	return nil
 }
 
 func (c *Client) Team(id string) {
 	c.teamId = id
 }
 
+// BotUser sets the app's bot user ID, used as the author of app messages and in mentions.
+func (c *Client) BotUser(id string) {
+	c.botUserId = id
+}
+
+// OptionsLoadUrl sets the app URL for external select options, the interactions URL by default.
+func (c *Client) OptionsLoadUrl(url string) {
+	c.appClient.OptionsLoadUrl(url)
+}
+
 func (c *Client) RegisterUser(user *slack.User) {
 	c.users[user.ID] = user
 }
@@ -135,129 +363,98 @@ func (c *Client) Start(port string) error {
 }
 
 func (c *Client) User(t *testing.T, id string) User {
-	ch := make(chan *slack.ModalViewRequest, 1)
+	ch := make(chan *view, 1)
 
 	c.pagesByTriggers[id] = ch
 
-	uc := userClient{
+	uc := &userClient{
 		userId:     id,
 		client:     c.appClient,
 		pageUpdate: ch,
+		viewUpdate: make(chan struct{}, 1),
 		teamId:     c.teamId,
-		messages:   c.messagesByUser[id],
 		_client:    c,
 		user:       c.users[id],
+		t:          t,
 	}
 
-	page := page{
-		state: map[string]map[string]slack.BlockAction{},
-		actionCallback: func(actionId string, typ slack.InteractionType, waitModal bool, state map[string]map[string]slack.BlockAction, value string) {
-			triggerId, _ := gonanoid.Nanoid()
-			c.viewsByTrigger[triggerId] = make(chan *slack.ModalViewRequest, 1)
-
-			var view slack.View
-
-			if len(uc.viewsStack) > 0 {
-				view = slack.View{
-					PrivateMetadata: uc.viewsStack[len(uc.viewsStack)-1].PrivateMetadata,
-					State: &slack.ViewState{
-						Values: state,
-					},
-				}
-			}
-
-			event := slack.InteractionCallback{
-				Type:        typ,
-				Token:       "",
-				CallbackID:  "",
-				ResponseURL: "",
-				TriggerID:   triggerId,
-				ActionTs:    "",
-				Team: slack.Team{
-					ID: c.teamId,
-				},
-				Channel:                  slack.Channel{},
-				User:                     *c.users[id],
-				OriginalMessage:          slack.Message{},
-				Message:                  slack.Message{},
-				Name:                     "",
-				Value:                    "",
-				MessageTs:                "",
-				AttachmentID:             "",
-				ActionCallback:           slack.ActionCallbacks{},
-				View:                     view,
-				ActionID:                 "",
-				APIAppID:                 "",
-				BlockID:                  "",
-				Container:                slack.Container{},
-				DialogSubmissionCallback: slack.DialogSubmissionCallback{},
-				ViewSubmissionCallback:   slack.ViewSubmissionCallback{},
-				ViewClosedCallback:       slack.ViewClosedCallback{},
-				RawState:                 nil,
-			}
-
-			event.ActionCallback.BlockActions = append(event.ActionCallback.BlockActions, &slack.BlockAction{
-				ActionID: actionId,
-				Value:    value,
-			})
-
-			resp, err := c.appClient.SendInteractionAction(&event)
-			if err != nil {
-				t.Fatal(err)
-				return
-			}
-
-			if waitModal {
-				view := <-c.viewsByTrigger[triggerId]
-				uc.currentModal = view
-				uc.viewsStack = append(uc.viewsStack, view)
-				uc.set(view.Blocks)
-			} else {
-				go func() {
-					view := <-c.viewsByTrigger[triggerId]
-					uc.currentModal = view
-					uc.viewsStack = append(uc.viewsStack, view)
-					uc.set(view.Blocks)
-				}()
-			}
-
-			// TODO form maybe error
-			if event.Type == slack.InteractionTypeViewSubmission {
-				if resp != nil {
-					switch resp.ResponseAction {
-					case slack.RAUpdate:
-						uc.viewsStack[len(uc.viewsStack)-1] = resp.View
-						uc.page.set(resp.View.Blocks)
-					}
-				} else {
-					uc.viewsStack = uc.viewsStack[:len(uc.viewsStack)-1]
-					if len(uc.viewsStack) == 0 {
-						if uc.home != nil {
-							uc.page.set(uc.home.Blocks)
-						}
-					} else {
-						uc.page.set(uc.viewsStack[len(uc.viewsStack)-1].Blocks)
-					}
-				}
-			}
-		},
+	uc.page = page{
+		state:           map[string]map[string]slack.BlockAction{},
+		actionCallback:  uc.viewAction,
+		store:           c,
+		optionsCallback: uc.viewOptions,
 	}
 
-	uc.page = page
-
-	return &uc
+	return uc
 }
 
 type AppHttpClient struct {
 	eventUrl       string
 	interactionUrl string
+	commandUrl     string
+	optionsUrl     string
 	client         *http.Client
 	signed         string
 	teamId         string
 }
 
-func NewAppHttpClient(eventUrl string, interactionUrl string, signedSecret string, teamId string) *AppHttpClient {
-	return &AppHttpClient{eventUrl: eventUrl, interactionUrl: interactionUrl, client: &http.Client{}, teamId: teamId, signed: signedSecret}
+func NewAppHttpClient(eventUrl string, interactionUrl string, commandUrl string, signedSecret string, teamId string) *AppHttpClient {
+	return &AppHttpClient{eventUrl: eventUrl, interactionUrl: interactionUrl, commandUrl: commandUrl, client: &http.Client{}, teamId: teamId, signed: signedSecret}
+}
+
+// SendCommand posts a slash command and returns the synchronous response,
+// nil when the app only acknowledged it with an empty body.
+func (c *AppHttpClient) SendCommand(command *slack.SlashCommand) (*slack.Msg, error) {
+	f := url.Values{}
+	f.Set("token", command.Token)
+	f.Set("team_id", command.TeamID)
+	f.Set("team_domain", command.TeamDomain)
+	f.Set("channel_id", command.ChannelID)
+	f.Set("channel_name", command.ChannelName)
+	f.Set("user_id", command.UserID)
+	f.Set("user_name", command.UserName)
+	f.Set("command", command.Command)
+	f.Set("text", command.Text)
+	f.Set("response_url", command.ResponseURL)
+	f.Set("trigger_id", command.TriggerID)
+	f.Set("api_app_id", command.APIAppID)
+
+	req, _ := http.NewRequest("POST", c.commandUrl, strings.NewReader(f.Encode()))
+
+	secret, timestamp := generateSecret(c.signed, f.Encode())
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Signature", secret)
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("status code not 200, is %v", res.StatusCode)
+	}
+
+	bResp, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bytes.TrimSpace(bResp)) == 0 {
+		return nil, nil
+	}
+
+	var msg slack.Msg
+
+	// Slack shows a plain text body as an ephemeral message.
+	if err := json.Unmarshal(bResp, &msg); err != nil {
+		return &slack.Msg{Text: string(bResp)}, nil
+	}
+
+	return &msg, nil
 }
 
 func (c *AppHttpClient) SendInteractionAction(event *slack.InteractionCallback) (*slack.ViewSubmissionResponse, error) {
@@ -267,7 +464,7 @@ func (c *AppHttpClient) SendInteractionAction(event *slack.InteractionCallback)
 	}
 
 	f := url.Values{}
-	f.Set("payload", string(b))
+	f.Set("payload", string(richTextPayload(b)))
 
 	req, _ := http.NewRequest("POST", c.interactionUrl, strings.NewReader(f.Encode()))
 
@@ -306,6 +503,68 @@ func (c *AppHttpClient) SendInteractionAction(event *slack.InteractionCallback)
 	return &viewResponse, nil
 }
 
+// OptionsLoadUrl sets the URL block_suggestion requests are sent to, the interactions URL by default.
+func (c *AppHttpClient) OptionsLoadUrl(url string) {
+	c.optionsUrl = url
+}
+
+// LoadOptions sends a block_suggestion request for an external select and returns
+// the options of the response, flattening option_groups.
+func (c *AppHttpClient) LoadOptions(event *slack.InteractionCallback) ([]*slack.OptionBlockObject, error) {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	f := url.Values{}
+	f.Set("payload", string(richTextPayload(b)))
+
+	optionsUrl := c.optionsUrl
+	if optionsUrl == "" {
+		optionsUrl = c.interactionUrl
+	}
+
+	req, _ := http.NewRequest("POST", optionsUrl, strings.NewReader(f.Encode()))
+
+	secret, timestamp := generateSecret(c.signed, f.Encode())
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Signature", secret)
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("status code not 200, is %v", res.StatusCode)
+	}
+
+	bResp, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var optionsResponse struct {
+		slack.OptionsResponse
+		slack.OptionGroupsResponse
+	}
+
+	if err := json.Unmarshal(bResp, &optionsResponse); err != nil {
+		return nil, fmt.Errorf("cannot parse options response: %w", err)
+	}
+
+	options := optionsResponse.Options
+	for _, group := range optionsResponse.OptionGroups {
+		options = append(options, group.Options...)
+	}
+
+	return options, nil
+}
+
 func (c *AppHttpClient) PushEvent(event interface{}) error {
 	b, err := json.Marshal(event)
 	if err != nil {
@@ -0,0 +1,111 @@
+package slacktest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEphemeralMessages(t *testing.T) {
+	app := newTestApp(t, nil)
+	channel := app.channel("general", "U1", "U2")
+
+	_, err := app.api.PostEphemeral(channel, "U2", slack.MsgOptionBlocks(testSection("only for you")))
+	require.NoError(t, err)
+
+	second := app.client.User(t, "U2")
+	require.Len(t, second.EphemeralMessages(), 1)
+	second.EphemeralMessages().Last().SearchByText(t, "only for you")
+	assert.Len(t, second.Messages(), 0)
+
+	assert.Len(t, app.client.User(t, "U1").EphemeralMessages(), 0)
+}
+
+func TestEphemeralMessagesWhilePosting(t *testing.T) {
+	app := newTestApp(t, nil)
+	channel := app.channel("general", "U1")
+	user := app.client.User(t, "U1")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 10; i++ {
+			if _, err := app.api.PostEphemeral(channel, "U1", slack.MsgOptionText(fmt.Sprintf("message %d", i), false)); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		user.EphemeralMessages()
+	}
+
+	wg.Wait()
+
+	assert.Len(t, user.EphemeralMessages(), 10)
+}
+
+func TestDeleteMessage(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	channel, ts, err := app.api.PostMessage("U1", slack.MsgOptionBlocks(testSection("to delete")))
+	require.NoError(t, err)
+
+	user := app.client.User(t, "U1")
+	msg := user.Messages().Last()
+	assert.False(t, msg.IsDeleted())
+
+	_, _, err = app.api.DeleteMessage(channel, ts)
+	require.NoError(t, err)
+
+	msg.WaitDeleted(t)
+	assert.True(t, msg.IsDeleted())
+	assert.Len(t, user.Messages(), 0)
+
+	_, _, err = app.api.DeleteMessage(channel, ts)
+	assert.EqualError(t, err, "message_not_found")
+
+	_, _, err = app.api.DeleteMessage("C404", ts)
+	assert.EqualError(t, err, "channel_not_found")
+}
+
+func TestThreads(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	channel, ts, err := app.api.PostMessage("U1", slack.MsgOptionText("parent", false))
+	require.NoError(t, err)
+
+	_, _, err = app.api.PostMessage(channel, slack.MsgOptionText("reply", false), slack.MsgOptionTS(ts))
+	require.NoError(t, err)
+
+	_, _, err = app.api.PostMessage(channel, slack.MsgOptionText("broadcast", false), slack.MsgOptionTS(ts), slack.MsgOptionBroadcast())
+	require.NoError(t, err)
+
+	user := app.client.User(t, "U1")
+	require.Len(t, user.Messages(), 2, "replies stay in the thread unless broadcast")
+
+	parent := user.Messages()[0]
+	assert.Len(t, parent.Thread(), 2)
+
+	reply := parent.Reply(t, "from user")
+	require.NotNil(t, reply)
+
+	event, ok := app.waitEvent(t).(*slackevents.MessageEvent)
+	require.True(t, ok)
+	assert.Equal(t, "from user", event.Text)
+	assert.Equal(t, ts, event.ThreadTimeStamp)
+	assert.Equal(t, "im", event.ChannelType)
+
+	messages, _, _, err := app.api.GetConversationReplies(&slack.GetConversationRepliesParameters{ChannelID: channel, Timestamp: ts})
+	require.NoError(t, err)
+	require.Len(t, messages, 4)
+	assert.Equal(t, 3, messages[0].ReplyCount)
+}
@@ -0,0 +1,83 @@
+package slacktest
+
+import (
+	"github.com/slack-go/slack"
+	"testing"
+)
+
+// confirmation is an action waiting for the user to answer its confirm dialog.
+type confirmation struct {
+	dialog *slack.ConfirmationBlockObject
+	action func()
+}
+
+// guard runs the action, or opens the confirm dialog when the element has one.
+func (p *page) guard(dialog *slack.ConfirmationBlockObject, action func()) {
+	if dialog == nil {
+		action()
+		return
+	}
+
+	p.confirmation = &confirmation{
+		dialog: dialog,
+		action: action,
+	}
+}
+
+// ConfirmationDialog returns the open confirm dialog, nil if there is none.
+func (p *page) ConfirmationDialog() *slack.ConfirmationBlockObject {
+	if p.confirmation == nil {
+		return nil
+	}
+
+	return p.confirmation.dialog
+}
+
+// Confirm clicks the confirm button of the open dialog, sending the guarded action to the app.
+func (p *page) Confirm(t *testing.T) {
+	if p.confirmation == nil {
+		t.Fatal("no confirmation dialog is open")
+		return
+	}
+
+	c := p.confirmation
+	p.confirmation = nil
+
+	c.action()
+}
+
+// Deny clicks the deny button of the open dialog. Nothing is sent to the app.
+func (p *page) Deny(t *testing.T) {
+	if p.confirmation == nil {
+		t.Fatal("no confirmation dialog is open")
+		return
+	}
+
+	p.confirmation = nil
+}
+
+// elementConfirm returns the confirm dialog of the element, nil if it has none.
+func elementConfirm(element slack.BlockElement) *slack.ConfirmationBlockObject {
+	switch x := element.(type) {
+	case *slack.ButtonBlockElement:
+		return x.Confirm
+	case *slack.SelectBlockElement:
+		return x.Confirm
+	case *slack.MultiSelectBlockElement:
+		return x.Confirm
+	case *slack.OverflowBlockElement:
+		return x.Confirm
+	case *slack.DatePickerBlockElement:
+		return x.Confirm
+	case *slack.TimePickerBlockElement:
+		return x.Confirm
+	case *slack.DateTimePickerBlockElement:
+		return x.Confirm
+	case *slack.CheckboxGroupsBlockElement:
+		return x.Confirm
+	case *slack.RadioButtonsBlockElement:
+		return x.Confirm
+	}
+
+	return nil
+}
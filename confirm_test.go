@@ -0,0 +1,39 @@
+package slacktest
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmationDialog(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	button := slack.NewButtonBlockElement("delete", "1", plainText("Delete"))
+	button.Confirm = slack.NewConfirmationBlockObject(plainText("Sure?"), plainText("Really"), plainText("Yes"), plainText("No"))
+
+	_, _, err := app.api.PostMessage("U1", slack.MsgOptionBlocks(slack.NewActionBlock("actions", button)))
+	require.NoError(t, err)
+
+	msg := app.client.User(t, "U1").Messages().Last()
+	assert.Nil(t, msg.ConfirmationDialog())
+
+	msg.ClickByText(t, "Delete", false)
+	require.NotNil(t, msg.ConfirmationDialog())
+	assert.Equal(t, "Sure?", msg.ConfirmationDialog().Title.Text)
+	assert.Len(t, app.interactions, 0, "the action waits for the dialog")
+
+	msg.Deny(t)
+	assert.Nil(t, msg.ConfirmationDialog())
+	assert.Len(t, app.interactions, 0, "denied actions are not sent")
+
+	msg.ClickByText(t, "Delete", false)
+	msg.Confirm(t)
+	assert.Nil(t, msg.ConfirmationDialog())
+
+	action := app.waitInteraction(t, slack.InteractionTypeBlockActions).ActionCallback.BlockActions[0]
+	assert.Equal(t, "delete", action.ActionID)
+	assert.Equal(t, "1", action.Value)
+}
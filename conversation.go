@@ -0,0 +1,590 @@
+package slacktest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	gonanoid "github.com/matoous/go-nanoid"
+	"github.com/slack-go/slack"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const idAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+type conversation struct {
+	channel  *slack.Channel
+	messages *messages
+}
+
+func (cv *conversation) isMember(userId string) bool {
+	for _, member := range cv.channel.Members {
+		if member == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cv *conversation) addMember(userId string) {
+	if cv.isMember(userId) {
+		return
+	}
+
+	cv.channel.Members = append(cv.channel.Members, userId)
+	cv.channel.NumMembers = len(cv.channel.Members)
+}
+
+func (cv *conversation) removeMember(userId string) bool {
+	for i, member := range cv.channel.Members {
+		if member == userId {
+			cv.channel.Members = append(cv.channel.Members[:i], cv.channel.Members[i+1:]...)
+			cv.channel.NumMembers = len(cv.channel.Members)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cv *conversation) hasMembers(users []string) bool {
+	if len(cv.channel.Members) != len(users) {
+		return false
+	}
+
+	for _, user := range users {
+		if !cv.isMember(user) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// channelType returns the channel_type of message events sent from this conversation.
+func (cv *conversation) channelType() string {
+	switch {
+	case cv.channel.IsIM:
+		return "im"
+	case cv.channel.IsMpIM:
+		return "mpim"
+	case cv.channel.IsPrivate:
+		return "group"
+	default:
+		return "channel"
+	}
+}
+
+// name returns the channel name as Slack shows it in payloads.
+func (cv *conversation) name() string {
+	if cv.channel.IsIM {
+		return "directmessage"
+	}
+
+	return cv.channel.Name
+}
+
+// filterType returns the conversation type as named in select filters.
+func (cv *conversation) filterType() string {
+	switch {
+	case cv.channel.IsIM:
+		return "im"
+	case cv.channel.IsMpIM:
+		return "mpim"
+	case cv.channel.IsPrivate:
+		return "private"
+	default:
+		return "public"
+	}
+}
+
+func newId(prefix string) string {
+	return prefix + gonanoid.MustGenerate(idAlphabet, 10)
+}
+
+// RegisterConversation adds a channel, private group, DM or MPIM to the mock.
+// An empty ID is generated from the conversation type.
+func (c *Client) RegisterConversation(channel *slack.Channel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.addConversation(channel)
+}
+
+func (c *Client) addConversation(channel *slack.Channel) *conversation {
+	if channel.ID == "" {
+		switch {
+		case channel.IsIM:
+			channel.ID = newId("D")
+		case channel.IsMpIM, channel.IsPrivate:
+			channel.ID = newId("G")
+		default:
+			channel.ID = newId("C")
+		}
+	}
+
+	if channel.Created == 0 {
+		channel.Created = slack.JSONTime(time.Now().Unix())
+	}
+
+	channel.IsChannel = !channel.IsIM && !channel.IsMpIM && !channel.IsPrivate
+	channel.IsGroup = channel.IsPrivate && !channel.IsMpIM
+	channel.NumMembers = len(channel.Members)
+
+	cv := &conversation{
+		channel:  channel,
+		messages: &messages{},
+	}
+
+	c.conversations[channel.ID] = cv
+
+	return cv
+}
+
+// conversation returns the conversation by ID. A registered user ID resolves
+// to the DM with that user, as Slack does for chat.postMessage.
+func (c *Client) conversation(id string) *conversation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cv, ok := c.conversations[id]; ok {
+		return cv
+	}
+
+	if _, ok := c.users[id]; ok {
+		cv, _ := c.openConversationLocked([]string{id})
+		return cv
+	}
+
+	return nil
+}
+
+// openConversation returns the DM (one user) or MPIM (several users) with
+// exactly these members, creating it if needed.
+func (c *Client) openConversation(users []string) (*conversation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.openConversationLocked(users)
+}
+
+func (c *Client) openConversationLocked(users []string) (*conversation, bool) {
+	isIM := len(users) == 1
+
+	for _, cv := range c.conversations {
+		if cv.channel.IsIM == isIM && cv.channel.IsMpIM == !isIM && cv.hasMembers(users) {
+			return cv, true
+		}
+	}
+
+	channel := &slack.Channel{}
+	channel.IsOpen = true
+	channel.IsIM = isIM
+	channel.IsMpIM = !isIM
+	channel.IsPrivate = !isIM
+	channel.Members = append([]string{}, users...)
+
+	if isIM {
+		channel.User = users[0]
+	} else {
+		channel.Name = "mpdm-" + strings.Join(users, "--") + "-1"
+		channel.NameNormalized = channel.Name
+	}
+
+	return c.addConversation(channel), false
+}
+
+func (c *Client) createConversation(name string, isPrivate bool) (*conversation, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case name == "":
+		return nil, "invalid_name_required"
+	case len(name) > 80:
+		return nil, "invalid_name_maxlength"
+	case strings.ToLower(name) != name || strings.ContainsAny(name, " .,"):
+		return nil, "invalid_name_specials"
+	}
+
+	for _, cv := range c.conversations {
+		if cv.channel.Name == name {
+			return nil, "name_taken"
+		}
+	}
+
+	channel := &slack.Channel{}
+	channel.Name = name
+	channel.NameNormalized = name
+	channel.IsPrivate = isPrivate
+
+	return c.addConversation(channel), ""
+}
+
+// selectableConversation checks that a conversations select with the filter, or a channels
+// select when channels is set, lists the conversation.
+func (c *Client) selectableConversation(id string, channels bool, filter *slack.SelectBlockElementFilter) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cv, ok := c.conversations[id]
+	if !ok {
+		return fmt.Errorf("conversation %s not found", id)
+	}
+
+	if channels {
+		if cv.filterType() != "public" {
+			return fmt.Errorf("conversation %s is not a public channel", id)
+		}
+
+		return nil
+	}
+
+	if filter == nil {
+		return nil
+	}
+
+	if len(filter.Include) > 0 {
+		included := false
+		for _, typ := range filter.Include {
+			included = included || typ == cv.filterType()
+		}
+
+		if !included {
+			return fmt.Errorf("conversation %s is %s, the select includes %s", id, cv.filterType(), strings.Join(filter.Include, ", "))
+		}
+	}
+
+	if filter.ExcludeExternalSharedChannels && cv.channel.IsExtShared {
+		return fmt.Errorf("conversation %s is shared with an external organization", id)
+	}
+
+	if filter.ExcludeBotUsers && cv.channel.IsIM {
+		if user, ok := c.users[cv.channel.User]; ok && user.IsBot {
+			return fmt.Errorf("conversation %s is a DM with the bot user %s", id, user.ID)
+		}
+	}
+
+	return nil
+}
+
+// rawBlock mirrors the JSON of a block to read the element fields slack-go does not decode.
+type rawBlock struct {
+	Element   *rawElement  `json:"element"`
+	Accessory *rawElement  `json:"accessory"`
+	Elements  []rawElement `json:"elements"`
+}
+
+type rawElement struct {
+	ActionID string                          `json:"action_id"`
+	Filter   *slack.SelectBlockElementFilter `json:"filter"`
+}
+
+// keepFilters remembers the filter of multi conversation selects from the JSON of the
+// blocks, as slack-go only decodes it for single selects.
+func (c *Client) keepFilters(decoded slack.Blocks, raw []byte) {
+	var rawBlocks []rawBlock
+	if err := json.Unmarshal(raw, &rawBlocks); err != nil || len(rawBlocks) != len(decoded.BlockSet) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, block := range decoded.BlockSet {
+		var elements []slack.BlockElement
+		var rawElements []rawElement
+
+		switch x := block.(type) {
+		case *slack.InputBlock:
+			if rawBlocks[i].Element != nil {
+				elements, rawElements = []slack.BlockElement{x.Element}, []rawElement{*rawBlocks[i].Element}
+			}
+		case *slack.SectionBlock:
+			if x.Accessory != nil && rawBlocks[i].Accessory != nil {
+				elements, rawElements = []slack.BlockElement{accessoryElement(x.Accessory)}, []rawElement{*rawBlocks[i].Accessory}
+			}
+		case *slack.ActionBlock:
+			if x.Elements != nil {
+				elements, rawElements = x.Elements.ElementSet, rawBlocks[i].Elements
+			}
+		}
+
+		for j, element := range elements {
+			x, ok := element.(*slack.MultiSelectBlockElement)
+			if ok && j < len(rawElements) && rawElements[j].ActionID == x.ActionID && rawElements[j].Filter != nil {
+				c.filters[x] = rawElements[j].Filter
+			}
+		}
+	}
+}
+
+// multiSelectFilter returns the filter the app set on the multi conversation select.
+func (c *Client) multiSelectFilter(element *slack.MultiSelectBlockElement) *slack.SelectBlockElementFilter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.filters[element]
+}
+
+// viewBlocks returns the JSON of the view blocks in a views.* request body.
+func viewBlocks(body []byte) []byte {
+	var request struct {
+		View struct {
+			Blocks json.RawMessage `json:"blocks"`
+		} `json:"view"`
+	}
+
+	json.Unmarshal(body, &request)
+
+	return request.View.Blocks
+}
+
+// messageBlocks returns the JSON of the message blocks in a response_url request body.
+func messageBlocks(body []byte) []byte {
+	var request struct {
+		Blocks json.RawMessage `json:"blocks"`
+	}
+
+	json.Unmarshal(body, &request)
+
+	return request.Blocks
+}
+
+// userConversations returns every conversation the user is a member of.
+func (c *Client) userConversations(userId string) []*conversation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var res []*conversation
+
+	for _, cv := range c.conversations {
+		if cv.isMember(userId) {
+			res = append(res, cv)
+		}
+	}
+
+	return res
+}
+
+func (c *Client) postMessage(cv *conversation, msg *slack.Msg) *message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	msg.Channel = cv.channel.ID
+	if msg.Timestamp == "" {
+		msg.Timestamp = c.newTimestampLocked()
+	}
+
+	// Replies always belong to the thread root, and an unknown thread_ts posts to the channel.
+	if msg.ThreadTimestamp != "" {
+		if parent := cv.message(msg.ThreadTimestamp); parent == nil {
+			msg.ThreadTimestamp = ""
+			msg.SubType = ""
+		} else if parent.slackMessage.ThreadTimestamp != "" {
+			msg.ThreadTimestamp = parent.slackMessage.ThreadTimestamp
+		}
+	}
+
+	m := newMessage(msg)
+	cv.messages.List = append(cv.messages.List, m)
+
+	return m
+}
+
+// postCommandResponse stores a slash command response: in_channel responses are
+// posted to the conversation by the app, others are ephemeral for the user.
+func (c *Client) postCommandResponse(cv *conversation, userId string, msg *slack.Msg) *message {
+	msg.Type = "message"
+	msg.Channel = cv.channel.ID
+
+	if msg.ResponseType == slack.ResponseTypeInChannel {
+		msg.User = c.botUserId
+		msg.BotID = BotId
+		return c.postMessage(cv, msg)
+	}
+
+	return c.postEphemeral(userId, msg)
+}
+
+func (cv *conversation) message(ts string) *message {
+	for _, msg := range cv.messages.List {
+		if msg.slackMessage.Timestamp == ts {
+			return msg
+		}
+	}
+
+	return nil
+}
+
+func (cv *conversation) replies(ts string) []*message {
+	var res []*message
+
+	for _, msg := range cv.messages.List {
+		if msg.slackMessage.ThreadTimestamp == ts && msg.slackMessage.Timestamp != ts && !msg.isDeleted() {
+			res = append(res, msg)
+		}
+	}
+
+	return res
+}
+
+// history returns top-level messages and thread broadcasts, newest first.
+func (cv *conversation) history() []*message {
+	var res []*message
+
+	for i := len(cv.messages.List) - 1; i >= 0; i-- {
+		msg := cv.messages.List[i]
+		if msg.isDeleted() {
+			continue
+		}
+
+		threadTs := msg.slackMessage.ThreadTimestamp
+		if threadTs == "" || threadTs == msg.slackMessage.Timestamp || msg.slackMessage.SubType == "thread_broadcast" {
+			res = append(res, msg)
+		}
+	}
+
+	return res
+}
+
+// slackMessage renders a stored message the way the Web API returns it,
+// with thread metadata on parent messages.
+func (cv *conversation) slackMessage(msg *message) slack.Message {
+	res := slack.Message{Msg: *msg.slackMessage}
+	res.Channel = ""
+	res.Type = "message"
+
+	if replies := cv.replies(msg.slackMessage.Timestamp); len(replies) > 0 {
+		res.ThreadTimestamp = msg.slackMessage.Timestamp
+		res.ReplyCount = len(replies)
+
+		for _, reply := range replies {
+			res.Replies = append(res.Replies, slack.Reply{
+				User:      reply.slackMessage.User,
+				Timestamp: reply.slackMessage.Timestamp,
+			})
+		}
+	}
+
+	return res
+}
+
+// filterMessages keeps messages between oldest and latest, both "seconds.microseconds"
+// timestamps where empty means unbounded.
+func filterMessages(list []*message, oldest string, latest string, inclusive bool) []*message {
+	var res []*message
+
+	oldestTs, latestTs := parseTimestamp(oldest), parseTimestamp(latest)
+
+	for _, msg := range list {
+		ts := parseTimestamp(msg.slackMessage.Timestamp)
+
+		if oldest != "" && (ts < oldestTs || !inclusive && ts == oldestTs) {
+			continue
+		}
+
+		if latest != "" && (ts > latestTs || !inclusive && ts == latestTs) {
+			continue
+		}
+
+		res = append(res, msg)
+	}
+
+	return res
+}
+
+func parseTimestamp(ts string) int64 {
+	parts := strings.SplitN(ts, ".", 2)
+
+	sec, _ := strconv.ParseInt(parts[0], 10, 64)
+	res := sec * 1e6
+
+	if len(parts) == 2 {
+		micro := (parts[1] + "000000")[:6]
+		usec, _ := strconv.ParseInt(micro, 10, 64)
+		res += usec
+	}
+
+	return res
+}
+
+// newTimestamp returns a unique, increasing message timestamp in the Slack
+// "seconds.microseconds" format.
+func (c *Client) newTimestamp() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.newTimestampLocked()
+}
+
+func (c *Client) newTimestampLocked() string {
+	now := time.Now().UnixNano() / int64(time.Microsecond)
+	if now <= c.lastTimestamp {
+		now = c.lastTimestamp + 1
+	}
+
+	c.lastTimestamp = now
+
+	return fmt.Sprintf("%d.%06d", now/1e6, now%1e6)
+}
+
+func sortMessages(list []*message) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].slackMessage.Timestamp < list[j].slackMessage.Timestamp
+	})
+}
+
+// paginate returns the page bounds for a cursor produced by a previous call
+// and the cursor of the next page, empty on the last one. A cursor that is not
+// an offset returns the invalid_cursor error code.
+func paginate(total int, cursor string, limit int) (int, int, string, string) {
+	start := 0
+
+	if cursor != "" {
+		b, err := base64.StdEncoding.DecodeString(cursor)
+		if err != nil || !strings.HasPrefix(string(b), "offset:") {
+			return 0, 0, "", "invalid_cursor"
+		}
+
+		start, err = strconv.Atoi(strings.TrimPrefix(string(b), "offset:"))
+		if err != nil || start < 0 {
+			return 0, 0, "", "invalid_cursor"
+		}
+	}
+
+	if start > total {
+		start = total
+	}
+
+	if limit <= 0 {
+		limit = 100
+	}
+
+	end := start + limit
+	if end >= total {
+		return start, total, "", ""
+	}
+
+	return start, end, base64.StdEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(end))), ""
+}
+
+func splitList(value string) []string {
+	var res []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
+
+// isTrue reports whether a boolean argument is set, as 1 or true.
+func isTrue(value string) bool {
+	return value == "1" || value == "true"
+}
@@ -0,0 +1,220 @@
+package slacktest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversations(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	channel, err := app.api.CreateConversation(slack.CreateConversationParams{ChannelName: "general"})
+	require.NoError(t, err)
+
+	_, err = app.api.CreateConversation(slack.CreateConversationParams{ChannelName: "general"})
+	assert.EqualError(t, err, "name_taken")
+
+	_, err = app.api.InviteUsersToConversation(channel.ID, "U1", "U2")
+	require.NoError(t, err)
+
+	_, err = app.api.InviteUsersToConversation(channel.ID, "U1")
+	assert.EqualError(t, err, "already_in_channel")
+
+	_, _, err = app.api.PostMessage(channel.ID, slack.MsgOptionBlocks(testSection("hello channel")))
+	require.NoError(t, err)
+
+	app.client.User(t, "U2").Messages().Last().SearchByText(t, "hello channel")
+
+	require.NoError(t, app.api.KickUserFromConversation(channel.ID, "U2"))
+	assert.EqualError(t, app.api.KickUserFromConversation(channel.ID, "U2"), "not_in_channel")
+	assert.Len(t, app.client.User(t, "U2").Messages(), 0)
+
+	require.NoError(t, app.api.ArchiveConversation(channel.ID))
+	assert.EqualError(t, app.api.ArchiveConversation(channel.ID), "already_archived")
+
+	_, _, err = app.api.PostMessage(channel.ID, slack.MsgOptionText("archived", false))
+	assert.EqualError(t, err, "is_archived")
+
+	info, err := app.api.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: channel.ID})
+	require.NoError(t, err)
+	assert.True(t, info.IsArchived)
+}
+
+func TestConversationsDirectMessages(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	channel, _, err := app.api.PostMessage("U1", slack.MsgOptionText("hi", false))
+	require.NoError(t, err)
+
+	im, _, alreadyOpen, err := app.api.OpenConversation(&slack.OpenConversationParameters{Users: []string{"U1"}})
+	require.NoError(t, err)
+	assert.True(t, alreadyOpen)
+	assert.Equal(t, channel, im.ID)
+	assert.True(t, im.IsIM)
+
+	mpim, _, _, err := app.api.OpenConversation(&slack.OpenConversationParameters{Users: []string{"U1", "U2"}})
+	require.NoError(t, err)
+	assert.True(t, mpim.IsMpIM)
+
+	_, _, _, err = app.api.OpenConversation(&slack.OpenConversationParameters{Users: []string{"unknown"}})
+	assert.EqualError(t, err, "user_not_found")
+}
+
+func TestConversationMembers(t *testing.T) {
+	app := newTestApp(t, nil)
+	channel := app.channel("general", "U1", "U2")
+
+	members, cursor, err := app.api.GetUsersInConversation(&slack.GetUsersInConversationParameters{ChannelID: channel, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"U1"}, members)
+	require.NotEmpty(t, cursor)
+
+	members, cursor, err = app.api.GetUsersInConversation(&slack.GetUsersInConversationParameters{ChannelID: channel, Limit: 1, Cursor: cursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"U2"}, members)
+	assert.Empty(t, cursor)
+}
+
+func TestConversationMembersInvalidCursor(t *testing.T) {
+	app := newTestApp(t, nil)
+	channel := app.channel("general", "U1", "U2")
+
+	for _, cursor := range []string{
+		base64.StdEncoding.EncodeToString([]byte("offset:-5")),
+		base64.StdEncoding.EncodeToString([]byte("offset:two")),
+		base64.StdEncoding.EncodeToString([]byte("page:1")),
+		"not base64!",
+	} {
+		_, _, err := app.api.GetUsersInConversation(&slack.GetUsersInConversationParameters{ChannelID: channel, Cursor: cursor})
+		assert.EqualError(t, err, "invalid_cursor", cursor)
+	}
+}
+
+func TestConversationHistory(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	var channel string
+	var timestamps []string
+
+	for _, text := range []string{"first", "second", "third"} {
+		ch, ts, err := app.api.PostMessage("U1", slack.MsgOptionText(text, false))
+		require.NoError(t, err)
+
+		channel = ch
+		timestamps = append(timestamps, ts)
+	}
+
+	res, err := app.api.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: channel, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, res.Messages, 2)
+	assert.True(t, res.HasMore)
+	assert.Equal(t, timestamps[2], res.Messages[0].Timestamp)
+
+	res, err = app.api.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: channel, Limit: 2, Cursor: res.ResponseMetaData.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, res.Messages, 1)
+	assert.False(t, res.HasMore)
+	assert.Equal(t, timestamps[0], res.Messages[0].Timestamp)
+
+	res, err = app.api.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: channel, Oldest: timestamps[0]})
+	require.NoError(t, err)
+	assert.Len(t, res.Messages, 2)
+
+	res, err = app.api.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: channel, Oldest: timestamps[0], Inclusive: true})
+	require.NoError(t, err)
+	assert.Len(t, res.Messages, 3)
+}
+
+func TestConversationHistoryInclusive(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	channel, ts, err := app.api.PostMessage("U1", slack.MsgOptionText("first", false))
+	require.NoError(t, err)
+
+	for _, inclusive := range []string{"1", "true"} {
+		messages, errCode := app.messages(t, "conversations.history", url.Values{
+			"channel":   {channel},
+			"oldest":    {ts},
+			"latest":    {ts},
+			"inclusive": {inclusive},
+		})
+		assert.Empty(t, errCode)
+		assert.Len(t, messages, 1, inclusive)
+	}
+
+	for _, inclusive := range []string{"", "0", "false"} {
+		messages, errCode := app.messages(t, "conversations.history", url.Values{
+			"channel":   {channel},
+			"oldest":    {ts},
+			"inclusive": {inclusive},
+		})
+		assert.Empty(t, errCode)
+		assert.Len(t, messages, 0, inclusive)
+	}
+}
+
+func TestConversationReplies(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	channel, ts, err := app.api.PostMessage("U1", slack.MsgOptionText("parent", false))
+	require.NoError(t, err)
+
+	_, replyTs, err := app.api.PostMessage(channel, slack.MsgOptionText("reply", false), slack.MsgOptionTS(ts))
+	require.NoError(t, err)
+
+	messages, _, _, err := app.api.GetConversationReplies(&slack.GetConversationRepliesParameters{ChannelID: channel, Timestamp: ts})
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, ts, messages[0].Timestamp)
+	assert.Equal(t, replyTs, messages[1].Timestamp)
+
+	replies, errCode := app.messages(t, "conversations.replies", url.Values{
+		"channel":   {channel},
+		"ts":        {ts},
+		"oldest":    {replyTs},
+		"inclusive": {"true"},
+	})
+	assert.Empty(t, errCode)
+	assert.Len(t, replies, 2)
+
+	_, _, _, err = app.api.GetConversationReplies(&slack.GetConversationRepliesParameters{ChannelID: channel, Timestamp: "1.000001"})
+	assert.EqualError(t, err, "thread_not_found")
+}
+
+func TestConversationHistoryInvalidCursor(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	channel, ts, err := app.api.PostMessage("U1", slack.MsgOptionText("parent", false))
+	require.NoError(t, err)
+
+	cursor := base64.StdEncoding.EncodeToString([]byte("offset:-5"))
+
+	_, err = app.api.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: channel, Cursor: cursor})
+	assert.EqualError(t, err, "invalid_cursor")
+
+	_, _, _, err = app.api.GetConversationReplies(&slack.GetConversationRepliesParameters{ChannelID: channel, Timestamp: ts, Cursor: cursor})
+	assert.EqualError(t, err, "invalid_cursor")
+}
+
+// messages calls conversations.history or conversations.replies with raw form values.
+func (app *testApp) messages(t *testing.T, method string, values url.Values) ([]slack.Message, string) {
+	resp, err := http.PostForm(app.apiUrl+method, values)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var res struct {
+		Ok       bool            `json:"ok"`
+		Error    string          `json:"error"`
+		Messages []slack.Message `json:"messages"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+
+	return res.Messages, res.Error
+}
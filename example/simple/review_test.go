@@ -11,7 +11,7 @@ import (
 
 func TestReview(t *testing.T) {
 	teamId := fmt.Sprintf("%v", time.Now().UnixNano())
-	client := slacktest.NewClient("http://localhost:4000/api/slack/events", "http://localhost:4000/api/slack/actions", "847c94fba6f3caff5f5dafa9b23aaffb", teamId)
+	client := slacktest.NewClient("http://localhost:4000/api/slack/events", "http://localhost:4000/api/slack/actions", "http://localhost:4000/api/slack/commands", "847c94fba6f3caff5f5dafa9b23aaffb", teamId)
 	if err := client.Start(":4999"); err != nil {
 		panic(err)
 	}
@@ -7,6 +7,6 @@ require (
 	github.com/go-chi/chi/v5 v5.0.4
 	github.com/matoous/go-nanoid v1.5.0
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/slack-go/slack v0.9.4
-	github.com/stretchr/testify v1.7.0 // indirect
+	github.com/slack-go/slack v0.15.0
+	github.com/stretchr/testify v1.7.0
 )
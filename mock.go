@@ -7,7 +7,7 @@ import (
 	"github.com/slack-go/slack"
 	"io/ioutil"
 	"net/http"
-	"time"
+	"strconv"
 )
 
 func NewMock(c *Client) http.Handler {
@@ -40,18 +40,18 @@ func NewMock(c *Client) http.Handler {
 			return
 		}
 
+		c.keepFilters(inMessage.Blocks, messageBlocks(reqBytes))
+
 		inMessage.Channel = channel
 		inMessage.Timestamp = ts
 
-		userMessages := c.messagesByUser[inMessage.Channel]
-
-		for _, msg := range userMessages.List {
-			if msg.slackMessage.Timestamp == inMessage.Timestamp {
-				msg.slackMessage.Blocks = inMessage.Blocks
-				go func() {
-					msg.update <- struct{}{}
-				}()
-			}
+		if msg := c.findMessage(inMessage.Channel, inMessage.Timestamp); msg != nil && inMessage.DeleteOriginal {
+			msg.delete()
+		} else if msg != nil {
+			msg.slackMessage.Blocks = inMessage.Blocks
+			go func() {
+				msg.update <- struct{}{}
+			}()
 		}
 
 		res := struct {
@@ -77,6 +77,37 @@ func NewMock(c *Client) http.Handler {
 		w.Write(b)
 	})
 
+	router.Post("/api/commands/response_url/{channel}/{user}", func(w http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+
+		var inMessage slack.Msg
+
+		reqBytes, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(500)
+			return
+		}
+
+		if err := json.Unmarshal(reqBytes, &inMessage); err != nil {
+			w.WriteHeader(500)
+			return
+		}
+
+		c.keepFilters(inMessage.Blocks, messageBlocks(reqBytes))
+
+		cv := c.conversation(chi.URLParam(req, "channel"))
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
+		}
+
+		c.postCommandResponse(cv, chi.URLParam(req, "user"), &inMessage)
+
+		writeResponse(w, slack.SlackResponse{
+			Ok: true,
+		})
+	})
+
 	router.Post("/api/chat.update", func(w http.ResponseWriter, req *http.Request) {
 		req.ParseForm()
 
@@ -90,18 +121,25 @@ func NewMock(c *Client) http.Handler {
 			return
 		}
 
-		userMessages := c.messagesByUser[inMessage.Channel]
+		c.keepFilters(inMessage.Blocks, []byte(req.Form.Get("blocks")))
 
-		for _, msg := range userMessages.List {
-			currentMsg := msg
-			if msg.slackMessage.Timestamp == inMessage.Timestamp {
-				currentMsg.slackMessage.Blocks = inMessage.Blocks
-				go func() {
-					currentMsg.update <- struct{}{}
-				}()
-			}
+		cv := c.conversation(inMessage.Channel)
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
+		}
+
+		currentMsg := cv.message(inMessage.Timestamp)
+		if currentMsg == nil || currentMsg.isDeleted() {
+			writeError(w, "message_not_found")
+			return
 		}
 
+		currentMsg.slackMessage.Blocks = inMessage.Blocks
+		go func() {
+			currentMsg.update <- struct{}{}
+		}()
+
 		res := struct {
 			slack.SlackResponse
 			Channel string `json:"channel"`
@@ -110,7 +148,7 @@ func NewMock(c *Client) http.Handler {
 			SlackResponse: slack.SlackResponse{
 				Ok: true,
 			},
-			Channel: inMessage.Channel,
+			Channel: cv.channel.ID,
 			Ts:      inMessage.Timestamp,
 		}
 
@@ -127,32 +165,39 @@ func NewMock(c *Client) http.Handler {
 		req.ParseForm()
 
 		inMessage := slack.Msg{
-			Channel:   req.Form.Get("channel"),
-			Timestamp: fmt.Sprintf("%v", time.Now().UnixNano()),
+			Type:            "message",
+			User:            c.botUserId,
+			BotID:           BotId,
+			Channel:         req.Form.Get("channel"),
+			Text:            req.Form.Get("text"),
+			ThreadTimestamp: req.Form.Get("thread_ts"),
 		}
 
-		if err := json.Unmarshal([]byte(req.Form.Get("blocks")), &inMessage.Blocks); err != nil {
-			w.WriteHeader(500)
-			return
+		if inMessage.ThreadTimestamp != "" && req.Form.Get("reply_broadcast") == "true" {
+			inMessage.SubType = "thread_broadcast"
 		}
 
-		userMessages := c.messagesByUser[inMessage.Channel]
-		if userMessages == nil {
-			userMessages = &messages{}
-			c.messagesByUser[inMessage.Channel] = userMessages
+		if blocks := req.Form.Get("blocks"); blocks != "" {
+			if err := json.Unmarshal([]byte(blocks), &inMessage.Blocks); err != nil {
+				w.WriteHeader(500)
+				return
+			}
+
+			c.keepFilters(inMessage.Blocks, []byte(blocks))
 		}
 
-		_msg := message{
-			page: page{
-				state: map[string]map[string]slack.BlockAction{},
-			},
-			slackMessage: &inMessage,
-			update:       make(chan struct{}),
+		cv := c.conversation(inMessage.Channel)
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
 		}
 
-		_msg.page.set(inMessage.Blocks)
+		if cv.channel.IsArchived {
+			writeError(w, "is_archived")
+			return
+		}
 
-		userMessages.List = append(userMessages.List, &_msg)
+		c.postMessage(cv, &inMessage)
 
 		res := struct {
 			slack.SlackResponse
@@ -175,6 +220,354 @@ func NewMock(c *Client) http.Handler {
 		w.Write(b)
 	})
 
+	router.Post("/api/chat.delete", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+
+		cv := c.conversation(req.Form.Get("channel"))
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
+		}
+
+		msg := cv.message(req.Form.Get("ts"))
+		if msg == nil || msg.isDeleted() {
+			writeError(w, "message_not_found")
+			return
+		}
+
+		msg.delete()
+
+		writeResponse(w, struct {
+			slack.SlackResponse
+			Channel string `json:"channel"`
+			Ts      string `json:"ts"`
+		}{
+			SlackResponse: slack.SlackResponse{
+				Ok: true,
+			},
+			Channel: cv.channel.ID,
+			Ts:      msg.slackMessage.Timestamp,
+		})
+	})
+
+	router.Post("/api/chat.postEphemeral", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+
+		userId := req.Form.Get("user")
+		if _, ok := c.users[userId]; !ok {
+			writeError(w, "user_not_found")
+			return
+		}
+
+		cv := c.conversation(req.Form.Get("channel"))
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
+		}
+
+		if !cv.isMember(userId) {
+			writeError(w, "user_not_in_channel")
+			return
+		}
+
+		inMessage := slack.Msg{
+			Channel: cv.channel.ID,
+			Text:    req.Form.Get("text"),
+		}
+
+		if blocks := req.Form.Get("blocks"); blocks != "" {
+			if err := json.Unmarshal([]byte(blocks), &inMessage.Blocks); err != nil {
+				w.WriteHeader(500)
+				return
+			}
+
+			c.keepFilters(inMessage.Blocks, []byte(blocks))
+		}
+
+		c.postEphemeral(userId, &inMessage)
+
+		writeResponse(w, struct {
+			slack.SlackResponse
+			MessageTs string `json:"message_ts"`
+		}{
+			SlackResponse: slack.SlackResponse{
+				Ok: true,
+			},
+			MessageTs: inMessage.Timestamp,
+		})
+	})
+
+	router.Post("/api/conversations.open", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+
+		var cv *conversation
+		var alreadyOpen bool
+
+		if channel := req.Form.Get("channel"); channel != "" {
+			if cv = c.conversation(channel); cv == nil {
+				writeError(w, "channel_not_found")
+				return
+			}
+
+			alreadyOpen = true
+		} else {
+			users := splitList(req.Form.Get("users"))
+			if len(users) == 0 {
+				writeError(w, "users_list_not_supplied")
+				return
+			}
+
+			if len(users) > 8 {
+				writeError(w, "too_many_users")
+				return
+			}
+
+			for _, user := range users {
+				if _, ok := c.users[user]; !ok {
+					writeError(w, "user_not_found")
+					return
+				}
+			}
+
+			cv, alreadyOpen = c.openConversation(users)
+		}
+
+		writeResponse(w, struct {
+			slack.SlackResponse
+			NoOp        bool           `json:"no_op,omitempty"`
+			AlreadyOpen bool           `json:"already_open,omitempty"`
+			Channel     *slack.Channel `json:"channel"`
+		}{
+			SlackResponse: slack.SlackResponse{
+				Ok: true,
+			},
+			NoOp:        alreadyOpen,
+			AlreadyOpen: alreadyOpen,
+			Channel:     cv.channel,
+		})
+	})
+
+	router.Post("/api/conversations.create", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+
+		cv, errCode := c.createConversation(req.Form.Get("name"), req.Form.Get("is_private") == "true")
+		if errCode != "" {
+			writeError(w, errCode)
+			return
+		}
+
+		writeConversation(w, cv)
+	})
+
+	router.Post("/api/conversations.info", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+
+		cv := c.conversation(req.Form.Get("channel"))
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
+		}
+
+		writeConversation(w, cv)
+	})
+
+	router.Post("/api/conversations.members", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+
+		cv := c.conversation(req.Form.Get("channel"))
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		limit, _ := strconv.Atoi(req.Form.Get("limit"))
+		start, end, nextCursor, errCode := paginate(len(cv.channel.Members), req.Form.Get("cursor"), limit)
+		if errCode != "" {
+			writeError(w, errCode)
+			return
+		}
+
+		writeResponse(w, struct {
+			slack.SlackResponse
+			Members          []string `json:"members"`
+			ResponseMetadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}{
+			SlackResponse: slack.SlackResponse{
+				Ok: true,
+			},
+			Members: cv.channel.Members[start:end],
+			ResponseMetadata: struct {
+				NextCursor string `json:"next_cursor"`
+			}{
+				NextCursor: nextCursor,
+			},
+		})
+	})
+
+	router.Post("/api/conversations.invite", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+
+		cv := c.conversation(req.Form.Get("channel"))
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if cv.channel.IsIM || cv.channel.IsMpIM {
+			writeError(w, "method_not_supported_for_channel_type")
+			return
+		}
+
+		if cv.channel.IsArchived {
+			writeError(w, "is_archived")
+			return
+		}
+
+		users := splitList(req.Form.Get("users"))
+		if len(users) == 0 {
+			writeError(w, "no_user")
+			return
+		}
+
+		var invited bool
+
+		for _, user := range users {
+			if _, ok := c.users[user]; !ok {
+				writeError(w, "user_not_found")
+				return
+			}
+
+			if !cv.isMember(user) {
+				invited = true
+			}
+		}
+
+		if !invited {
+			writeError(w, "already_in_channel")
+			return
+		}
+
+		for _, user := range users {
+			cv.addMember(user)
+		}
+
+		writeConversation(w, cv)
+	})
+
+	router.Post("/api/conversations.kick", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+
+		cv := c.conversation(req.Form.Get("channel"))
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if cv.channel.IsIM || cv.channel.IsMpIM {
+			writeError(w, "method_not_supported_for_channel_type")
+			return
+		}
+
+		user := req.Form.Get("user")
+		if _, ok := c.users[user]; !ok {
+			writeError(w, "user_not_found")
+			return
+		}
+
+		removed := cv.removeMember(user)
+
+		if !removed {
+			writeError(w, "not_in_channel")
+			return
+		}
+
+		writeResponse(w, slack.SlackResponse{
+			Ok: true,
+		})
+	})
+
+	router.Post("/api/conversations.archive", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+
+		cv := c.conversation(req.Form.Get("channel"))
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if cv.channel.IsIM || cv.channel.IsMpIM {
+			writeError(w, "method_not_supported_for_channel_type")
+			return
+		}
+
+		if cv.channel.IsArchived {
+			writeError(w, "already_archived")
+			return
+		}
+
+		cv.channel.IsArchived = true
+
+		writeResponse(w, slack.SlackResponse{
+			Ok: true,
+		})
+	})
+
+	router.Post("/api/conversations.history", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+
+		cv := c.conversation(req.Form.Get("channel"))
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		list := filterMessages(cv.history(), req.Form.Get("oldest"), req.Form.Get("latest"), isTrue(req.Form.Get("inclusive")))
+
+		writeMessages(w, cv, list, req)
+	})
+
+	router.Post("/api/conversations.replies", func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+
+		cv := c.conversation(req.Form.Get("channel"))
+		if cv == nil {
+			writeError(w, "channel_not_found")
+			return
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		parent := cv.message(req.Form.Get("ts"))
+		if parent == nil || parent.isDeleted() {
+			writeError(w, "thread_not_found")
+			return
+		}
+
+		// The parent message is always returned first, regardless of filters.
+		list := filterMessages(cv.replies(parent.slackMessage.Timestamp), req.Form.Get("oldest"), req.Form.Get("latest"), isTrue(req.Form.Get("inclusive")))
+		list = append([]*message{parent}, list...)
+
+		writeMessages(w, cv, list, req)
+	})
+
 	router.Post("/api/views.publish", func(w http.ResponseWriter, req *http.Request) {
 		defer req.Body.Close()
 
@@ -195,22 +588,55 @@ func NewMock(c *Client) http.Handler {
 			return
 		}
 
-		c.pagesByTriggers[request.UserID] <- &request.View
+		c.keepFilters(request.View.Blocks, viewBlocks(b))
 
-		resp, err := json.Marshal(slack.ViewResponse{
-			SlackResponse: slack.SlackResponse{
-				Ok: true,
-			},
-		})
+		home, errCode := c.publishHome(request.UserID, request.View, request.Hash)
+		if errCode != "" {
+			writeError(w, errCode)
+			return
+		}
+
+		c.pagesByTriggers[request.UserID] <- home
+
+		writeView(w, c, home)
+	})
+
+	router.Post("/api/views.open", func(w http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+
+		b, err := ioutil.ReadAll(req.Body)
 		if err != nil {
 			w.WriteHeader(500)
 			return
 		}
 
-		w.Write(resp)
+		reqBody := struct {
+			TriggerID string                 `json:"trigger_id"`
+			View      slack.ModalViewRequest `json:"view"`
+		}{}
+
+		if err := json.Unmarshal(b, &reqBody); err != nil {
+			w.WriteHeader(500)
+			return
+		}
+
+		c.keepFilters(reqBody.View.Blocks, viewBlocks(b))
+
+		tr, errCode := c.exchangeTrigger(reqBody.TriggerID)
+		if errCode != "" {
+			writeError(w, errCode)
+			return
+		}
+
+		// The user owns the view before it sees it: the app may update it in the same request.
+		modal := c.addView(reqBody.View)
+		c.own(modal, tr.user)
+		tr.views <- modal
+
+		writeView(w, c, modal)
 	})
 
-	router.Post("/api/views.open", func(w http.ResponseWriter, req *http.Request) {
+	router.Post("/api/views.push", func(w http.ResponseWriter, req *http.Request) {
 		defer req.Body.Close()
 
 		b, err := ioutil.ReadAll(req.Body)
@@ -229,19 +655,84 @@ func NewMock(c *Client) http.Handler {
 			return
 		}
 
-		c.viewsByTrigger[reqBody.TriggerID] <- &reqBody.View
+		c.keepFilters(reqBody.View.Blocks, viewBlocks(b))
 
-		resB, err := json.Marshal(slack.ViewResponse{
-			SlackResponse: slack.SlackResponse{
-				Ok: true,
-			},
-		})
+		tr := c.trigger(reqBody.TriggerID)
+		if tr != nil && len(tr.user.viewsStack) >= maxViewsStack {
+			writeError(w, "push_limit_reached")
+			return
+		}
+
+		tr, errCode := c.exchangeTrigger(reqBody.TriggerID)
+		if errCode != "" {
+			writeError(w, errCode)
+			return
+		}
+
+		// The user owns the view before it sees it: the app may update it in the same request.
+		modal := c.addView(reqBody.View)
+		c.own(modal, tr.user)
+		tr.views <- modal
+
+		writeView(w, c, modal)
+	})
+
+	router.Post("/api/views.update", func(w http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+
+		b, err := ioutil.ReadAll(req.Body)
 		if err != nil {
 			w.WriteHeader(500)
 			return
 		}
 
-		w.Write(resB)
+		reqBody := struct {
+			View       slack.ModalViewRequest `json:"view"`
+			ViewID     string                 `json:"view_id"`
+			ExternalID string                 `json:"external_id"`
+			Hash       string                 `json:"hash"`
+		}{}
+
+		if err := json.Unmarshal(b, &reqBody); err != nil {
+			w.WriteHeader(500)
+			return
+		}
+
+		c.keepFilters(reqBody.View.Blocks, viewBlocks(b))
+
+		existing := c.findView(reqBody.ViewID, reqBody.ExternalID)
+		if existing == nil {
+			writeError(w, "not_found")
+			return
+		}
+
+		if reqBody.Hash != "" && reqBody.Hash != existing.Hash {
+			writeError(w, "hash_conflict")
+			return
+		}
+
+		updated, owner := c.updateView(existing, reqBody.View)
+		if owner != nil {
+			owner.notifyViewUpdate()
+		}
+
+		writeView(w, c, updated)
+	})
+
+	router.Post("/api/auth.test", func(w http.ResponseWriter, req *http.Request) {
+		writeResponse(w, struct {
+			slack.SlackResponse
+			TeamID string `json:"team_id"`
+			UserID string `json:"user_id"`
+			BotID  string `json:"bot_id"`
+		}{
+			SlackResponse: slack.SlackResponse{
+				Ok: true,
+			},
+			TeamID: c.teamId,
+			UserID: c.botUserId,
+			BotID:  BotId,
+		})
 	})
 
 	router.Post("/api/users.info", func(w http.ResponseWriter, req *http.Request) {
@@ -271,3 +762,78 @@ func NewMock(c *Client) http.Handler {
 
 	return router
 }
+
+func writeResponse(w http.ResponseWriter, res interface{}) {
+	b, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+// writeMessages writes a page of conversations.history or conversations.replies
+// using the cursor and limit form values.
+func writeMessages(w http.ResponseWriter, cv *conversation, list []*message, req *http.Request) {
+	limit, _ := strconv.Atoi(req.Form.Get("limit"))
+	start, end, nextCursor, errCode := paginate(len(list), req.Form.Get("cursor"), limit)
+	if errCode != "" {
+		writeError(w, errCode)
+		return
+	}
+
+	slackMessages := []slack.Message{}
+	for _, msg := range list[start:end] {
+		slackMessages = append(slackMessages, cv.slackMessage(msg))
+	}
+
+	writeResponse(w, struct {
+		slack.SlackResponse
+		Messages         []slack.Message `json:"messages"`
+		HasMore          bool            `json:"has_more"`
+		ResponseMetadata struct {
+			NextCursor string `json:"next_cursor"`
+		} `json:"response_metadata"`
+	}{
+		SlackResponse: slack.SlackResponse{
+			Ok: true,
+		},
+		Messages: slackMessages,
+		HasMore:  nextCursor != "",
+		ResponseMetadata: struct {
+			NextCursor string `json:"next_cursor"`
+		}{
+			NextCursor: nextCursor,
+		},
+	})
+}
+
+func writeView(w http.ResponseWriter, c *Client, v *view) {
+	writeResponse(w, slack.ViewResponse{
+		SlackResponse: slack.SlackResponse{
+			Ok: true,
+		},
+		View: v.payload(c.teamId, nil),
+	})
+}
+
+func writeConversation(w http.ResponseWriter, cv *conversation) {
+	writeResponse(w, struct {
+		slack.SlackResponse
+		Channel *slack.Channel `json:"channel"`
+	}{
+		SlackResponse: slack.SlackResponse{
+			Ok: true,
+		},
+		Channel: cv.channel,
+	})
+}
+
+func writeError(w http.ResponseWriter, code string) {
+	writeResponse(w, slack.SlackResponse{
+		Ok:    false,
+		Error: code,
+	})
+}
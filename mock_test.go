@@ -0,0 +1,218 @@
+package slacktest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const testSecret = "847c94fba6f3caff5f5dafa9b23aaffb"
+
+// testApp is the app under test: the mock sends it events, interactions and slash
+// commands, and it calls the mock Slack API with api.
+type testApp struct {
+	client *Client
+	api    *slack.Client
+	// apiUrl is the base URL of the mock Slack API, for requests slack-go cannot make.
+	apiUrl string
+	// events, interactions and commands receive every request once it is handled.
+	events       chan string
+	interactions chan slack.InteractionCallback
+	commands     chan url.Values
+}
+
+// appHandler answers a request of the app under test. kind is events, actions or commands.
+type appHandler func(app *testApp, kind string, body string, w http.ResponseWriter)
+
+// newTestApp starts the app and the mock Slack API on ephemeral ports, with users U1 and U2.
+func newTestApp(t *testing.T, handler appHandler) *testApp {
+	app := &testApp{
+		events:       make(chan string, 100),
+		interactions: make(chan slack.InteractionCallback, 100),
+		commands:     make(chan url.Values, 100),
+	}
+
+	appServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		kind := strings.TrimPrefix(req.URL.Path, "/")
+
+		if handler != nil {
+			handler(app, kind, string(b), w)
+		}
+
+		switch kind {
+		case "events":
+			app.events <- string(b)
+		case "actions":
+			app.interactions <- interaction(t, string(b))
+		case "commands":
+			values, _ := url.ParseQuery(string(b))
+			app.commands <- values
+		}
+	}))
+	t.Cleanup(appServer.Close)
+
+	app.client = NewClient(appServer.URL+"/events", appServer.URL+"/actions", appServer.URL+"/commands", testSecret, "T1")
+
+	mockServer := httptest.NewServer(NewMock(app.client))
+	t.Cleanup(mockServer.Close)
+
+	mockUrl, _ := url.Parse(mockServer.URL)
+	app.client.port = ":" + mockUrl.Port()
+	app.apiUrl = mockServer.URL + "/api/"
+	app.api = slack.New("xoxb-test", slack.OptionAPIURL(app.apiUrl))
+
+	app.client.RegisterUser(&slack.User{ID: "U1", Name: "first"})
+	app.client.RegisterUser(&slack.User{ID: "U2", Name: "second"})
+
+	return app
+}
+
+// newModalApp starts an app with an Open button in the home tab, which opens the modal.
+// The other requests go to handler.
+func newModalApp(t *testing.T, modal slack.ModalViewRequest, handler appHandler) *testApp {
+	return newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId, testButton("Open"))
+			return
+		}
+
+		if kind == "actions" {
+			callback := interaction(t, body)
+			if callback.Type == slack.InteractionTypeBlockActions && callback.ActionCallback.BlockActions[0].ActionID == "Open" {
+				if _, err := app.api.OpenView(callback.TriggerID, modal); err != nil {
+					t.Error(err)
+				}
+				return
+			}
+		}
+
+		if handler != nil {
+			handler(app, kind, body, w)
+		}
+	})
+}
+
+// openModal opens the modal of newModalApp as U1.
+func (app *testApp) openModal(t *testing.T) *userClient {
+	user := app.client.User(t, "U1").(*userClient)
+	user.HomeOpen(t)
+	user.ClickByText(t, "Open", true)
+
+	return user
+}
+
+// submit submits the open modal and returns the submitted state values.
+func (app *testApp) submit(t *testing.T, user User) map[string]map[string]slack.BlockAction {
+	user.SubmitForm()
+
+	return app.waitInteraction(t, slack.InteractionTypeViewSubmission).View.State.Values
+}
+
+// interaction decodes the payload of an interaction request.
+func interaction(t *testing.T, body string) slack.InteractionCallback {
+	var callback slack.InteractionCallback
+
+	values, _ := url.ParseQuery(body)
+	if err := json.Unmarshal([]byte(values.Get("payload")), &callback); err != nil {
+		t.Errorf("cannot decode interaction: %s", err)
+	}
+
+	return callback
+}
+
+// waitInteraction returns the next interaction of the type, skipping the others.
+func (app *testApp) waitInteraction(t *testing.T, typ slack.InteractionType) slack.InteractionCallback {
+	for {
+		select {
+		case callback := <-app.interactions:
+			if callback.Type == typ {
+				return callback
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatalf("wait %s interaction after 5 seconds", typ)
+			return slack.InteractionCallback{}
+		}
+	}
+}
+
+// waitEvent returns the inner event of the next event callback.
+func (app *testApp) waitEvent(t *testing.T) interface{} {
+	select {
+	case body := <-app.events:
+		event, err := slackevents.ParseEvent([]byte(body), slackevents.OptionNoVerifyToken())
+		if err != nil {
+			t.Fatalf("cannot decode event: %s", err)
+		}
+
+		return event.InnerEvent.Data
+	case <-time.After(time.Second * 5):
+		t.Fatal("wait event after 5 seconds")
+		return nil
+	}
+}
+
+// homeOpened returns the user of an app_home_opened event.
+func homeOpened(kind string, body string) (string, bool) {
+	if kind != "events" {
+		return "", false
+	}
+
+	event, err := slackevents.ParseEvent([]byte(body), slackevents.OptionNoVerifyToken())
+	if err != nil {
+		return "", false
+	}
+
+	opened, ok := event.InnerEvent.Data.(*slackevents.AppHomeOpenedEvent)
+	if !ok {
+		return "", false
+	}
+
+	return opened.User, true
+}
+
+// publishHome publishes the home tab of the user with the blocks.
+func (app *testApp) publishHome(t *testing.T, userId string, blocks ...slack.Block) {
+	_, err := app.api.PublishView(userId, slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}, "")
+	if err != nil {
+		t.Errorf("cannot publish home: %s", err)
+	}
+}
+
+// channel registers a public channel with the members.
+func (app *testApp) channel(name string, members ...string) string {
+	ch := &slack.Channel{}
+	ch.Name = name
+	ch.Members = members
+
+	app.client.RegisterConversation(ch)
+
+	return ch.ID
+}
+
+func testModal(blocks ...slack.Block) slack.ModalViewRequest {
+	return slack.ModalViewRequest{
+		Type:   slack.VTModal,
+		Title:  slack.NewTextBlockObject(slack.PlainTextType, "Modal", false, false),
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}
+}
+
+func testButton(text string) slack.Block {
+	return slack.NewActionBlock("", slack.NewButtonBlockElement(text, text, slack.NewTextBlockObject(slack.PlainTextType, text, false, false)))
+}
+
+func testSection(text string) slack.Block {
+	return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+}
@@ -12,6 +12,7 @@ type Page interface {
 	ClickByText(t *testing.T, text string, waitModal bool)
 	SearchByText(t *testing.T, text string) interface{}
 	Type(t *testing.T, searchText string, value string) interface{}
+	TypeAndEnter(t *testing.T, searchText string, value string) interface{}
 	Element(actionId string) Element
 	SubmitForm()
 	WaitHomeUpdate()
@@ -19,106 +20,570 @@ type Page interface {
 	SelectUserByText(t *testing.T, text string, user string)
 	SelectUsersByText(t *testing.T, text string, users []string)
 	SelectByText(t *testing.T, searchText string, value string)
+	SelectOptionsByText(t *testing.T, searchText string, values []string)
+	Check(t *testing.T, text string)
+	Uncheck(t *testing.T, text string)
+	ChooseRadio(t *testing.T, text string)
+	SelectConversation(t *testing.T, label string, conversation string)
+	SelectConversations(t *testing.T, label string, conversations []string)
+	SelectChannel(t *testing.T, label string, channel string)
+	SelectChannels(t *testing.T, label string, channels []string)
+	SelectExternal(t *testing.T, label string, query string, optionText string)
+	ChooseOverflowOption(t *testing.T, actionIdOrBlock string, optionText string)
+	PickDate(t *testing.T, label string, date time.Time)
+	PickTime(t *testing.T, label string, value time.Time)
+	PickDateTime(t *testing.T, label string, value time.Time)
 	Wait(duration time.Duration)
 	Messages() Messages
+	FormErrors() map[string]string
+	Confirm(t *testing.T)
+	Deny(t *testing.T)
+	ConfirmationDialog() *slack.ConfirmationBlockObject
+	AssertFieldError(t *testing.T, label string, message string)
 }
 
 type page struct {
 	page           slack.Blocks
 	raw            string
-	actionCallback func(actionId string, typ slack.InteractionType, waitModal bool, state map[string]map[string]slack.BlockAction, value string)
-	state          map[string]map[string]slack.BlockAction
+	actionCallback func(action *slack.BlockAction, typ slack.InteractionType, waitModal bool, state map[string]map[string]slack.BlockAction)
+	// store is the mock the page belongs to, used to look up conversations.
+	store *Client
+	// optionsCallback sends the block_suggestion request of an external select.
+	optionsCallback func(action *slack.BlockAction, state map[string]map[string]slack.BlockAction) ([]*slack.OptionBlockObject, error)
+	state           map[string]map[string]slack.BlockAction
+	errors          map[string]string
+	// confirmation is the open confirm dialog of the last clicked element.
+	confirmation *confirmation
 }
 
+// set shows new blocks, with input values starting from their initial values.
 func (p *page) set(block slack.Blocks) {
+	p.show(block, nil)
+}
+
+// refresh shows updated blocks of the same view, keeping the values of inputs that did not change.
+func (p *page) refresh(block slack.Blocks) {
+	p.show(block, p.state)
+}
+
+func (p *page) show(block slack.Blocks, state map[string]map[string]slack.BlockAction) {
 	p.page = block
+	p.errors = nil
+	p.confirmation = nil
+	p.state = blocks(block).values(state)
 	b, _ := json.Marshal(block)
 
 	p.raw = string(b)
 }
 
 func (p *page) SelectByText(t *testing.T, searchText string, value string) {
-	el := p.SearchByText(t, searchText)
+	el := blocks(p.page).locate(searchText)
+	if el == nil {
+		t.Fatalf("cannot search select with text=%s", searchText)
+		return
+	}
 
-	if x, ok := el.(*slack.InputBlock); ok {
-		p.state[x.BlockID] = map[string]slack.BlockAction{}
-		switch blockElement := x.Element.(type) {
-		case *slack.SelectBlockElement:
-			var hasValue bool
-			var finalValue string
-
-			for _, option := range blockElement.Options {
-				if option.Text.Text == value {
-					hasValue = true
-					finalValue = option.Value
-					break
-				}
-			}
+	switch x := el.element.(type) {
+	case *slack.SelectBlockElement:
+		if x.Type != slack.OptTypeStatic {
+			t.Fatalf("select with text=%s is not static, is %s", searchText, x.Type)
+			return
+		}
 
-			if !hasValue {
-				t.Fatal("value not found in select")
-				return
-			}
+		option := optionByText(allOptions(x.Options, x.OptionGroups), value)
+		if option == nil {
+			t.Fatal("value not found in select")
+			return
+		}
 
-			p.state[x.BlockID][blockElement.ActionID] = slack.BlockAction{
-				SelectedOption: slack.OptionBlockObject{
-					Value: finalValue,
-				},
-			}
+		selected := p.value(el)
+		selected.SelectedOption = *option
+
+		p.change(el, selected)
+	case *slack.MultiSelectBlockElement:
+		p.SelectOptionsByText(t, searchText, []string{value})
+	default:
+		t.Fatalf("element with text=%s is not select", searchText)
+	}
+}
+
+// SelectOptionsByText selects the options with the texts in the static multi select, replacing the selection.
+func (p *page) SelectOptionsByText(t *testing.T, searchText string, values []string) {
+	el := blocks(p.page).locate(searchText)
+	if el == nil {
+		t.Fatalf("cannot search select with text=%s", searchText)
+		return
+	}
+
+	x, ok := el.element.(*slack.MultiSelectBlockElement)
+	if !ok || x.Type != slack.MultiOptTypeStatic {
+		t.Fatalf("element with text=%s is not static multi select", searchText)
+		return
+	}
+
+	if x.MaxSelectedItems != nil && len(values) > *x.MaxSelectedItems {
+		t.Fatalf("select with text=%s allows %d options, got %d", searchText, *x.MaxSelectedItems, len(values))
+		return
+	}
+
+	options := allOptions(x.Options, x.OptionGroups)
+
+	selected := p.value(el)
+	selected.SelectedOptions = []slack.OptionBlockObject{}
+
+	for _, value := range values {
+		option := optionByText(options, value)
+		if option == nil {
+			t.Fatalf("value %s not found in select", value)
+			return
 		}
+
+		selected.SelectedOptions = append(selected.SelectedOptions, *option)
 	}
+
+	p.change(el, selected)
 }
 
 func (p *page) SelectUsersByText(t *testing.T, text string, users []string) {
-	el := p.SearchByText(t, text)
+	el := blocks(p.page).locate(text)
+	if el == nil {
+		t.Fatalf("cannot search element with text=%s", text)
+		return
+	}
 
-	if x, ok := el.(*slack.InputBlock); ok {
-		p.state[x.BlockID] = map[string]slack.BlockAction{}
-		switch blockElement := x.Element.(type) {
-		case *slack.MultiSelectBlockElement:
-			if blockElement.Type != slack.MultiOptTypeUser {
-				t.Fatal("input is not single user")
-				return
-			}
-			p.state[x.BlockID][blockElement.ActionID] = slack.BlockAction{SelectedUsers: users}
-		default:
-			t.Fatal("input is not user selector")
+	switch blockElement := el.element.(type) {
+	case *slack.MultiSelectBlockElement:
+		if blockElement.Type != slack.MultiOptTypeUser {
+			t.Fatal("input is not single user")
 			return
 		}
+
+		selected := p.value(el)
+		selected.SelectedUsers = users
+
+		p.change(el, selected)
+	default:
+		t.Fatal("input is not user selector")
+		return
 	}
 }
 
 func (p *page) SelectUserByText(t *testing.T, text string, user string) {
-	el := p.SearchByText(t, text)
+	el := blocks(p.page).locate(text)
+	if el == nil {
+		t.Fatalf("cannot search element with text=%s", text)
+		return
+	}
 
-	if x, ok := el.(*slack.InputBlock); ok {
-		p.state[x.BlockID] = map[string]slack.BlockAction{}
-		switch blockElement := x.Element.(type) {
-		case *slack.SelectBlockElement:
-			if blockElement.Type != slack.OptTypeUser {
-				t.Fatal(fmt.Sprintf("input is not single user, is %s", blockElement.Type))
-				return
-			}
-			p.state[x.BlockID][blockElement.ActionID] = slack.BlockAction{SelectedUser: user}
-		default:
-			t.Fatal("input is not user selector")
+	switch blockElement := el.element.(type) {
+	case *slack.SelectBlockElement:
+		if blockElement.Type != slack.OptTypeUser {
+			t.Fatal(fmt.Sprintf("input is not single user, is %s", blockElement.Type))
 			return
 		}
+
+		selected := p.value(el)
+		selected.SelectedUser = user
+
+		p.change(el, selected)
+	default:
+		t.Fatal("input is not user selector")
+		return
 	}
 }
 
+// Type types the value into the text input. With dispatch_action the app gets
+// block_actions if the input triggers actions on_character_entered.
 func (p *page) Type(t *testing.T, searchText string, value string) interface{} {
-	el := p.SearchByText(t, searchText)
+	return p.typeText(t, searchText, value, "on_character_entered")
+}
 
-	if x, ok := el.(*slack.InputBlock); ok {
-		p.state[x.BlockID] = map[string]slack.BlockAction{}
-		switch blockElement := x.Element.(type) {
-		case *slack.PlainTextInputBlockElement:
-			p.state[x.BlockID][blockElement.ActionID] = slack.BlockAction{Value: value}
+// TypeAndEnter types the value into the text input and presses enter. With dispatch_action
+// the app gets block_actions if the input triggers actions on_enter_pressed, the default.
+func (p *page) TypeAndEnter(t *testing.T, searchText string, value string) interface{} {
+	return p.typeText(t, searchText, value, "on_enter_pressed")
+}
+
+func (p *page) typeText(t *testing.T, searchText string, value string, trigger string) interface{} {
+	el := blocks(p.page).locate(searchText)
+	if el == nil {
+		t.Fatalf("cannot search element with text=%s", searchText)
+		return nil
+	}
+
+	if config, ok := textInputConfig(el.element); ok {
+		el.dispatch = el.dispatch && triggersActionsOn(config, trigger)
+
+		typed := p.value(el)
+		typed.Value = value
+
+		if _, ok := el.element.(*slack.RichTextInputBlockElement); ok {
+			typed.Value = richTextValue(value)
+		}
+
+		p.change(el, typed)
+	}
+
+	if res := blocks(p.page).SearchByText(searchText); res != nil {
+		return res
+	}
+
+	return el.element
+}
+
+// textInputConfig returns the dispatch_action_config of an element the user types text into.
+func textInputConfig(element slack.BlockElement) (*slack.DispatchActionConfig, bool) {
+	switch x := element.(type) {
+	case *slack.PlainTextInputBlockElement:
+		return x.DispatchActionConfig, true
+	case *slack.NumberInputBlockElement:
+		return x.DispatchActionConfig, true
+	case *slack.EmailTextInputBlockElement:
+		return x.DispatchActionConfig, true
+	case *slack.URLTextInputBlockElement:
+		return x.DispatchActionConfig, true
+	case *slack.RichTextInputBlockElement:
+		return x.DispatchActionConfig, true
+	}
+
+	return nil, false
+}
+
+// triggersActionsOn reports whether a dispatching input sends block_actions on the trigger.
+func triggersActionsOn(config *slack.DispatchActionConfig, trigger string) bool {
+	if config == nil || len(config.TriggerActionsOn) == 0 {
+		return trigger == "on_enter_pressed"
+	}
+
+	for _, on := range config.TriggerActionsOn {
+		if on == trigger {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check ticks the checkbox with the option text.
+func (p *page) Check(t *testing.T, text string) {
+	p.toggle(t, text, true)
+}
+
+// Uncheck clears the checkbox with the option text.
+func (p *page) Uncheck(t *testing.T, text string) {
+	p.toggle(t, text, false)
+}
+
+func (p *page) toggle(t *testing.T, text string, checked bool) {
+	el := blocks(p.page).locate(text)
+	if el == nil {
+		t.Fatalf("cannot search checkbox with text=%s", text)
+		return
+	}
+
+	x, ok := el.element.(*slack.CheckboxGroupsBlockElement)
+	if !ok {
+		t.Fatalf("element with text=%s is not checkboxes", text)
+		return
+	}
+
+	option := optionByText(x.Options, text)
+	if option == nil {
+		t.Fatalf("checkbox with text=%s not found", text)
+		return
+	}
+
+	value := p.value(el)
+
+	selected := map[string]bool{}
+	for _, o := range value.SelectedOptions {
+		selected[o.Value] = true
+	}
+	selected[option.Value] = checked
+
+	value.SelectedOptions = []slack.OptionBlockObject{}
+	for _, o := range x.Options {
+		if selected[o.Value] {
+			value.SelectedOptions = append(value.SelectedOptions, *o)
+		}
+	}
+
+	p.change(el, value)
+}
+
+// ChooseRadio selects the radio button with the option text.
+func (p *page) ChooseRadio(t *testing.T, text string) {
+	el := blocks(p.page).locate(text)
+	if el == nil {
+		t.Fatalf("cannot search radio button with text=%s", text)
+		return
+	}
+
+	x, ok := el.element.(*slack.RadioButtonsBlockElement)
+	if !ok {
+		t.Fatalf("element with text=%s is not radio buttons", text)
+		return
+	}
+
+	option := optionByText(x.Options, text)
+	if option == nil {
+		t.Fatalf("radio button with text=%s not found", text)
+		return
+	}
+
+	value := p.value(el)
+	value.SelectedOption = *option
+
+	p.change(el, value)
+}
+
+// SelectConversation selects the conversation in the conversations select with the label or placeholder.
+func (p *page) SelectConversation(t *testing.T, label string, conversation string) {
+	p.selectConversations(t, label, []string{conversation}, false, false)
+}
+
+// SelectConversations selects the conversations in the multi conversations select with the label or placeholder.
+func (p *page) SelectConversations(t *testing.T, label string, conversations []string) {
+	p.selectConversations(t, label, conversations, false, true)
+}
+
+// SelectChannel selects the public channel in the channels select with the label or placeholder.
+func (p *page) SelectChannel(t *testing.T, label string, channel string) {
+	p.selectConversations(t, label, []string{channel}, true, false)
+}
+
+// SelectChannels selects the public channels in the multi channels select with the label or placeholder.
+func (p *page) SelectChannels(t *testing.T, label string, channels []string) {
+	p.selectConversations(t, label, channels, true, true)
+}
+
+func (p *page) selectConversations(t *testing.T, label string, ids []string, channels bool, multi bool) {
+	el := blocks(p.page).locate(label)
+	if el == nil {
+		t.Fatalf("cannot search select with text=%s", label)
+		return
+	}
+
+	singleType, multiType := slack.OptTypeConversations, slack.MultiOptTypeConversations
+	if channels {
+		singleType, multiType = slack.OptTypeChannels, slack.MultiOptTypeChannels
+	}
+
+	var filter *slack.SelectBlockElementFilter
+
+	switch x := el.element.(type) {
+	case *slack.SelectBlockElement:
+		if multi || x.Type != singleType {
+			t.Fatalf("select with text=%s is %s", label, x.Type)
+			return
+		}
+		filter = x.Filter
+	case *slack.MultiSelectBlockElement:
+		if !multi || x.Type != multiType {
+			t.Fatalf("select with text=%s is %s", label, x.Type)
+			return
+		}
+
+		if x.MaxSelectedItems != nil && len(ids) > *x.MaxSelectedItems {
+			t.Fatalf("select with text=%s allows %d conversations, got %d", label, *x.MaxSelectedItems, len(ids))
+			return
+		}
+
+		filter = p.store.multiSelectFilter(x)
+	default:
+		t.Fatalf("element with text=%s is not select", label)
+		return
+	}
+
+	for _, id := range ids {
+		if err := p.store.selectableConversation(id, channels, filter); err != nil {
+			t.Fatalf("cannot select in %s: %v", label, err)
+			return
+		}
+	}
+
+	value := p.value(el)
+
+	switch {
+	case channels && multi:
+		value.SelectedChannels = ids
+	case channels:
+		value.SelectedChannel = ids[0]
+	case multi:
+		value.SelectedConversations = ids
+	default:
+		value.SelectedConversation = ids[0]
+	}
+
+	p.change(el, value)
+}
+
+// SelectExternal types the query into the external select with the label or placeholder,
+// loads the options from the app and selects the one with the option text.
+func (p *page) SelectExternal(t *testing.T, label string, query string, optionText string) {
+	el := blocks(p.page).locate(label)
+	if el == nil {
+		t.Fatalf("cannot search external select with text=%s", label)
+		return
+	}
+
+	var actionId string
+	var minQueryLength *int
+
+	switch x := el.element.(type) {
+	case *slack.SelectBlockElement:
+		if x.Type != slack.OptTypeExternal {
+			t.Fatalf("select with text=%s is not external, is %s", label, x.Type)
+			return
+		}
+		actionId, minQueryLength = x.ActionID, x.MinQueryLength
+	case *slack.MultiSelectBlockElement:
+		if x.Type != slack.MultiOptTypeExternal {
+			t.Fatalf("select with text=%s is not external, is %s", label, x.Type)
+			return
 		}
+		actionId, minQueryLength = x.ActionID, x.MinQueryLength
+	default:
+		t.Fatalf("element with text=%s is not external select", label)
+		return
+	}
+
+	// Slack waits for 3 characters unless the element sets min_query_length.
+	minLength := 3
+	if minQueryLength != nil {
+		minLength = *minQueryLength
+	}
+
+	if len(query) < minLength {
+		t.Fatalf("query %q is shorter than min_query_length of select with text=%s", query, label)
+		return
 	}
 
-	return el
+	options, err := p.optionsCallback(&slack.BlockAction{
+		ActionID: actionId,
+		BlockID:  el.blockId,
+		Value:    query,
+	}, p.state)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	option := optionByText(options, optionText)
+	if option == nil {
+		t.Fatalf("option with text=%s not loaded for query %q", optionText, query)
+		return
+	}
+
+	value := p.value(el)
+
+	x, ok := el.element.(*slack.MultiSelectBlockElement)
+	if !ok {
+		value.SelectedOption = *option
+		p.change(el, value)
+		return
+	}
+
+	// An option that is already selected is not offered again.
+	for _, selected := range value.SelectedOptions {
+		if selected.Value == option.Value {
+			return
+		}
+	}
+
+	if x.MaxSelectedItems != nil && len(value.SelectedOptions) >= *x.MaxSelectedItems {
+		t.Fatalf("select with text=%s allows %d options, got %d", label, *x.MaxSelectedItems, len(value.SelectedOptions)+1)
+		return
+	}
+
+	value.SelectedOptions = append(append([]slack.OptionBlockObject{}, value.SelectedOptions...), *option)
+
+	p.change(el, value)
+}
+
+// ChooseOverflowOption opens the overflow menu with the action_id or block_id and clicks the option.
+func (p *page) ChooseOverflowOption(t *testing.T, actionIdOrBlock string, optionText string) {
+	el := blocks(p.page).overflow(actionIdOrBlock)
+	if el == nil {
+		t.Fatalf("cannot search overflow menu with action or block=%s", actionIdOrBlock)
+		return
+	}
+
+	x := el.element.(*slack.OverflowBlockElement)
+
+	option := optionByText(x.Options, optionText)
+	if option == nil {
+		t.Fatalf("overflow option with text=%s not found", optionText)
+		return
+	}
+
+	action := &slack.BlockAction{
+		ActionID:       x.ActionID,
+		BlockID:        el.blockId,
+		Type:           slack.ActionType(x.Type),
+		SelectedOption: *option,
+	}
+
+	p.guard(x.Confirm, func() {
+		p.actionCallback(action, slack.InteractionTypeBlockActions, false, p.state)
+	})
+}
+
+// PickDate selects the date in the date picker with the label or placeholder.
+func (p *page) PickDate(t *testing.T, label string, date time.Time) {
+	el := blocks(p.page).locate(label)
+	if el == nil {
+		t.Fatalf("cannot search date picker with text=%s", label)
+		return
+	}
+
+	if _, ok := el.element.(*slack.DatePickerBlockElement); !ok {
+		t.Fatalf("element with text=%s is not date picker", label)
+		return
+	}
+
+	value := p.value(el)
+	value.SelectedDate = date.Format("2006-01-02")
+
+	p.change(el, value)
+}
+
+// PickTime selects the time, hours and minutes, in the time picker with the label or placeholder.
+func (p *page) PickTime(t *testing.T, label string, value time.Time) {
+	el := blocks(p.page).locate(label)
+	if el == nil {
+		t.Fatalf("cannot search time picker with text=%s", label)
+		return
+	}
+
+	if _, ok := el.element.(*slack.TimePickerBlockElement); !ok {
+		t.Fatalf("element with text=%s is not time picker", label)
+		return
+	}
+
+	selected := p.value(el)
+	selected.SelectedTime = value.Format("15:04")
+
+	p.change(el, selected)
+}
+
+// PickDateTime selects the date and time in the datetime picker with the label.
+func (p *page) PickDateTime(t *testing.T, label string, value time.Time) {
+	el := blocks(p.page).locate(label)
+	if el == nil {
+		t.Fatalf("cannot search datetime picker with text=%s", label)
+		return
+	}
+
+	if _, ok := el.element.(*slack.DateTimePickerBlockElement); !ok {
+		t.Fatalf("element with text=%s is not datetime picker", label)
+		return
+	}
+
+	selected := p.value(el)
+	selected.SelectedDateTime = value.Unix()
+
+	p.change(el, selected)
 }
 
 func (p *page) ClickByActionId(t *testing.T, actionId string, value string, waitModal bool) {
@@ -129,32 +594,79 @@ func (p *page) ClickByActionId(t *testing.T, actionId string, value string, wait
 
 	switch x := res.(type) {
 	case *slack.ButtonBlockElement:
-		p.actionCallback(x.ActionID, slack.InteractionTypeBlockActions, waitModal, p.state, x.Value)
+		action := p.buttonAction(x)
+		p.guard(x.Confirm, func() {
+			p.actionCallback(action, slack.InteractionTypeBlockActions, waitModal, p.state)
+		})
 	default:
 		t.Fatal("cannot click by element")
 	}
 }
 
+// ClickByText clicks the first button with the text. Other elements with the
+// same text, such as select or overflow options, do not hide the button.
 func (p *page) ClickByText(t *testing.T, text string, waitModal bool) {
-	res := blocks(p.page).SearchByText(text)
-	if res == nil {
-		t.Fatal(fmt.Sprintf("cannot search element with text=%s", text))
-		return
-	}
+	button := blocks(p.page).buttonByText(text)
+	if button == nil {
+		if blocks(p.page).SearchByText(text) == nil {
+			t.Fatal(fmt.Sprintf("cannot search element with text=%s", text))
+			return
+		}
 
-	switch x := res.(type) {
-	case *slack.ButtonBlockElement:
-		p.actionCallback(x.ActionID, slack.InteractionTypeBlockActions, waitModal, p.state, x.Value)
-	default:
 		t.Fatal("cannot click by element")
 		return
 	}
 
-	return
+	action := p.buttonAction(button)
+	p.guard(button.Confirm, func() {
+		p.actionCallback(action, slack.InteractionTypeBlockActions, waitModal, p.state)
+	})
 }
 
+// SubmitForm submits the view. Like the Slack client, it shows errors instead when
+// number, email or URL inputs have invalid values, and nothing is sent to the app.
 func (p *page) SubmitForm() {
-	p.actionCallback("", slack.InteractionTypeViewSubmission, false, p.state, "")
+	if errors := blocks(p.page).validate(p.state); len(errors) > 0 {
+		p.errors = errors
+		return
+	}
+
+	p.actionCallback(nil, slack.InteractionTypeViewSubmission, false, p.state)
+}
+
+// FormErrors returns errors of the last submission by block_id, as returned with response_action "errors".
+func (p *page) FormErrors() map[string]string {
+	return p.errors
+}
+
+// AssertFieldError checks the submission error shown under the input with the label or placeholder.
+func (p *page) AssertFieldError(t *testing.T, label string, message string) {
+	input, ok := blocks(p.page).inputByLabel(label)
+	if !ok {
+		t.Fatalf("cannot search input with label=%s", label)
+		return
+	}
+
+	if actual, ok := p.errors[input.BlockID]; !ok {
+		t.Fatalf("input %s has no error, want %q", label, message)
+	} else if actual != message {
+		t.Fatalf("input %s has error %q, want %q", label, actual, message)
+	}
+}
+
+func (p *page) buttonAction(x *slack.ButtonBlockElement) *slack.BlockAction {
+	action := &slack.BlockAction{
+		ActionID: x.ActionID,
+		BlockID:  blocks(p.page).blockIdOf(x),
+		Type:     slack.ActionType(x.Type),
+		Value:    x.Value,
+	}
+
+	if x.Text != nil {
+		action.Text = *x.Text
+	}
+
+	return action
 }
 
 func (p *page) SearchByText(t *testing.T, text string) interface{} {
@@ -183,28 +695,53 @@ func (b blocks) SearchByText(text string) interface{} {
 				return res
 			}
 		case *slack.InputBlock:
+			if hasText(x.Label, text) || hasText(x.Hint, text) {
+				return x
+			}
+
 			res := searchInBlockElements(&slack.BlockElements{ElementSet: []slack.BlockElement{x.Element}}, text)
 			if res != nil {
 				return x
 			}
 		case *slack.HeaderBlock:
-			if x.Text.Text == text {
+			if hasText(x.Text, text) {
+				return x
+			}
+		case *slack.RichTextBlock:
+			if richTextContains(x, text) {
+				return x
+			}
+		case *slack.ContextBlock:
+			for _, el := range x.ContextElements.Elements {
+				switch element := el.(type) {
+				case *slack.TextBlockObject:
+					if hasText(element, text) {
+						return x
+					}
+				case *slack.ImageBlockElement:
+					if element.AltText == text {
+						return x
+					}
+				}
+			}
+		case *slack.ImageBlock:
+			if x.AltText == text || hasText(x.Title, text) {
 				return x
 			}
 		case *slack.SectionBlock:
-			if x.Text.Text == text {
+			if hasText(x.Text, text) {
 				return x
 			}
 
-			if x.Accessory != nil {
-				var blockElement slack.BlockElement
-
-				if x.Accessory.ButtonElement != nil {
-					blockElement = x.Accessory.ButtonElement
+			for _, field := range x.Fields {
+				if hasText(field, text) {
+					return x
 				}
+			}
 
+			if x.Accessory != nil {
 				res := searchInBlockElements(&slack.BlockElements{
-					ElementSet: []slack.BlockElement{blockElement},
+					ElementSet: []slack.BlockElement{accessoryElement(x.Accessory)},
 				}, text)
 				if res != nil {
 					return res
@@ -216,23 +753,250 @@ func (b blocks) SearchByText(text string) interface{} {
 	return nil
 }
 
+// buttonByText finds the first button with the text in actions blocks and section accessories.
+func (b blocks) buttonByText(text string) *slack.ButtonBlockElement {
+	for _, block := range b.BlockSet {
+		switch x := block.(type) {
+		case *slack.ActionBlock:
+			if x.Elements == nil {
+				continue
+			}
+
+			for _, el := range x.Elements.ElementSet {
+				if button, ok := el.(*slack.ButtonBlockElement); ok && hasText(button.Text, text) {
+					return button
+				}
+			}
+		case *slack.SectionBlock:
+			if x.Accessory != nil && x.Accessory.ButtonElement != nil && hasText(x.Accessory.ButtonElement.Text, text) {
+				return x.Accessory.ButtonElement
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasText(object *slack.TextBlockObject, text string) bool {
+	return object != nil && object.Text == text
+}
+
+func (b blocks) inputByBlockId(blockId string) (*slack.InputBlock, bool) {
+	for _, block := range b.BlockSet {
+		if x, ok := block.(*slack.InputBlock); ok && x.BlockID == blockId {
+			return x, true
+		}
+	}
+
+	return nil, false
+}
+
+// inputByLabel finds the input block by its label, falling back to the element placeholder.
+func (b blocks) inputByLabel(label string) (*slack.InputBlock, bool) {
+	for _, block := range b.BlockSet {
+		if x, ok := block.(*slack.InputBlock); ok && hasText(x.Label, label) {
+			return x, true
+		}
+	}
+
+	x, ok := b.SearchByText(label).(*slack.InputBlock)
+
+	return x, ok
+}
+
+// element is an interactive element found on a page.
+type element struct {
+	blockId string
+	element slack.BlockElement
+	// dispatch is set when changing the element sends block_actions to the app:
+	// elements of actions blocks and section accessories, inputs with dispatch_action.
+	dispatch bool
+}
+
+// locate finds the interactive element by the label of its input, placeholder, text or option text.
+func (b blocks) locate(text string) *element {
+	matches := func(el slack.BlockElement) bool {
+		return el != nil && searchInBlockElements(&slack.BlockElements{ElementSet: []slack.BlockElement{el}}, text) != nil
+	}
+
+	for _, block := range b.BlockSet {
+		switch x := block.(type) {
+		case *slack.ActionBlock:
+			for _, el := range x.Elements.ElementSet {
+				if matches(el) {
+					return &element{blockId: x.BlockID, element: el, dispatch: true}
+				}
+			}
+		case *slack.InputBlock:
+			if hasText(x.Label, text) || hasText(x.Hint, text) || matches(x.Element) {
+				return &element{blockId: x.BlockID, element: x.Element, dispatch: x.DispatchAction}
+			}
+		case *slack.SectionBlock:
+			if x.Accessory != nil && matches(accessoryElement(x.Accessory)) {
+				return &element{blockId: x.BlockID, element: accessoryElement(x.Accessory), dispatch: true}
+			}
+		}
+	}
+
+	return nil
+}
+
+// overflow finds the overflow menu by its action_id or the block_id of the block holding it.
+func (b blocks) overflow(actionIdOrBlock string) *element {
+	matches := func(blockId string, el slack.BlockElement) bool {
+		x, ok := el.(*slack.OverflowBlockElement)
+		return ok && (x.ActionID == actionIdOrBlock || blockId == actionIdOrBlock)
+	}
+
+	for _, block := range b.BlockSet {
+		switch x := block.(type) {
+		case *slack.ActionBlock:
+			for _, el := range x.Elements.ElementSet {
+				if matches(x.BlockID, el) {
+					return &element{blockId: x.BlockID, element: el, dispatch: true}
+				}
+			}
+		case *slack.SectionBlock:
+			if x.Accessory != nil && x.Accessory.OverflowElement != nil && matches(x.BlockID, x.Accessory.OverflowElement) {
+				return &element{blockId: x.BlockID, element: x.Accessory.OverflowElement, dispatch: true}
+			}
+		}
+	}
+
+	return nil
+}
+
+// allOptions returns the options of a select, including the ones in option groups.
+func allOptions(options []*slack.OptionBlockObject, groups []*slack.OptionGroupBlockObject) []*slack.OptionBlockObject {
+	res := append([]*slack.OptionBlockObject{}, options...)
+
+	for _, group := range groups {
+		if group != nil {
+			res = append(res, group.Options...)
+		}
+	}
+
+	return res
+}
+
+func optionByText(options []*slack.OptionBlockObject, text string) *slack.OptionBlockObject {
+	for _, option := range options {
+		if option != nil && option.Text != nil && option.Text.Text == text {
+			return option
+		}
+	}
+
+	return nil
+}
+
+// blockIdOf returns the block_id of the block holding the element.
+func (b blocks) blockIdOf(element slack.BlockElement) string {
+	for _, block := range b.BlockSet {
+		switch x := block.(type) {
+		case *slack.ActionBlock:
+			for _, el := range x.Elements.ElementSet {
+				if el == element {
+					return x.BlockID
+				}
+			}
+		case *slack.InputBlock:
+			if x.Element == element {
+				return x.BlockID
+			}
+		case *slack.SectionBlock:
+			if x.Accessory != nil && accessoryElement(x.Accessory) == element {
+				return x.BlockID
+			}
+		}
+	}
+
+	return ""
+}
+
+func accessoryElement(accessory *slack.Accessory) slack.BlockElement {
+	switch {
+	case accessory.ButtonElement != nil:
+		return accessory.ButtonElement
+	case accessory.OverflowElement != nil:
+		return accessory.OverflowElement
+	case accessory.DatePickerElement != nil:
+		return accessory.DatePickerElement
+	case accessory.TimePickerElement != nil:
+		return accessory.TimePickerElement
+	case accessory.PlainTextInputElement != nil:
+		return accessory.PlainTextInputElement
+	case accessory.RadioButtonsElement != nil:
+		return accessory.RadioButtonsElement
+	case accessory.SelectElement != nil:
+		return accessory.SelectElement
+	case accessory.MultiSelectElement != nil:
+		return accessory.MultiSelectElement
+	case accessory.CheckboxGroupsBlockElement != nil:
+		return accessory.CheckboxGroupsBlockElement
+	case accessory.ImageElement != nil:
+		return accessory.ImageElement
+	}
+
+	return nil
+}
+
 func searchInBlockElements(elements *slack.BlockElements, text string) interface{} {
 	for _, el := range elements.ElementSet {
 		switch x := el.(type) {
 		case *slack.ButtonBlockElement:
-			if x.Text.Text == text {
+			if hasText(x.Text, text) {
+				return x
+			}
+		case *slack.ImageBlockElement:
+			if x.AltText == text {
 				return x
 			}
 		case *slack.PlainTextInputBlockElement:
-			if x.Placeholder.Text == text {
+			if hasText(x.Placeholder, text) {
 				return x
 			}
 		case *slack.SelectBlockElement:
-			if x.Placeholder.Text == text {
+			if hasText(x.Placeholder, text) || optionByText(allOptions(x.Options, x.OptionGroups), text) != nil {
 				return x
 			}
 		case *slack.MultiSelectBlockElement:
-			if x.Placeholder.Text == text {
+			if hasText(x.Placeholder, text) || optionByText(allOptions(x.Options, x.OptionGroups), text) != nil {
+				return x
+			}
+		case *slack.OverflowBlockElement:
+			if optionByText(x.Options, text) != nil {
+				return x
+			}
+		case *slack.RichTextInputBlockElement:
+			if hasText(x.Placeholder, text) {
+				return x
+			}
+		case *slack.NumberInputBlockElement:
+			if hasText(x.Placeholder, text) {
+				return x
+			}
+		case *slack.EmailTextInputBlockElement:
+			if hasText(x.Placeholder, text) {
+				return x
+			}
+		case *slack.URLTextInputBlockElement:
+			if hasText(x.Placeholder, text) {
+				return x
+			}
+		case *slack.DatePickerBlockElement:
+			if hasText(x.Placeholder, text) {
+				return x
+			}
+		case *slack.TimePickerBlockElement:
+			if hasText(x.Placeholder, text) {
+				return x
+			}
+		case *slack.CheckboxGroupsBlockElement:
+			if optionByText(x.Options, text) != nil {
+				return x
+			}
+		case *slack.RadioButtonsBlockElement:
+			if optionByText(x.Options, text) != nil {
 				return x
 			}
 		}
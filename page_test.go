@@ -0,0 +1,361 @@
+package slacktest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func plainText(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.PlainTextType, text, false, false)
+}
+
+func testOption(text string, value string) *slack.OptionBlockObject {
+	return slack.NewOptionBlockObject(value, plainText(text), nil)
+}
+
+func TestSelectExternal(t *testing.T) {
+	single := slack.NewOptionsSelectBlockElement(slack.OptTypeExternal, plainText("Find user"), "who")
+	multi := slack.NewOptionsMultiSelectBlockElement(slack.MultiOptTypeExternal, plainText("Find users"), "whom")
+
+	modal := testModal(
+		slack.NewInputBlock("who_block", plainText("Who"), nil, single),
+		slack.NewInputBlock("whom_block", plainText("Whom"), nil, multi),
+	)
+
+	app := newModalApp(t, modal, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if kind != "actions" {
+			return
+		}
+
+		if callback := interaction(t, body); callback.Type == slack.InteractionTypeBlockSuggestion {
+			writeResponse(w, slack.OptionsResponse{Options: []*slack.OptionBlockObject{
+				testOption("Alice", "U_ALICE"),
+				testOption("Bob", "U_BOB"),
+			}})
+		}
+	})
+
+	user := app.openModal(t)
+	user.SelectExternal(t, "Who", "ali", "Alice")
+	user.SelectExternal(t, "Whom", "ali", "Alice")
+	user.SelectExternal(t, "Whom", "bob", "Bob")
+	user.SelectExternal(t, "Whom", "ali", "Alice")
+
+	suggestion := app.waitInteraction(t, slack.InteractionTypeBlockSuggestion)
+	assert.Equal(t, "who", suggestion.ActionID)
+	assert.Equal(t, "ali", suggestion.Value)
+
+	values := app.submit(t, user)
+	assert.Equal(t, "U_ALICE", values["who_block"]["who"].SelectedOption.Value)
+
+	selected := values["whom_block"]["whom"].SelectedOptions
+	if assert.Len(t, selected, 2) {
+		assert.Equal(t, "U_ALICE", selected[0].Value)
+		assert.Equal(t, "U_BOB", selected[1].Value)
+	}
+}
+
+func TestSelectConversationsFilter(t *testing.T) {
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId, testButton("Open"))
+			return
+		}
+
+		if kind != "actions" {
+			return
+		}
+
+		// slack-go cannot encode the filter of multi selects, so the view is sent as JSON.
+		if callback := interaction(t, body); callback.Type == slack.InteractionTypeBlockActions {
+			resp, err := http.Post(app.apiUrl+"views.open", "application/json", strings.NewReader(`{
+				"trigger_id": "`+callback.TriggerID+`",
+				"view": {
+					"type": "modal",
+					"title": {"type": "plain_text", "text": "Share"},
+					"blocks": [{
+						"type": "input",
+						"block_id": "where_block",
+						"label": {"type": "plain_text", "text": "Where"},
+						"element": {
+							"type": "multi_conversations_select",
+							"action_id": "where",
+							"max_selected_items": 2,
+							"filter": {"include": ["public"], "exclude_bot_users": true}
+						}
+					}]
+				}
+			}`))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			resp.Body.Close()
+		}
+	})
+
+	general := app.channel("general", "U1")
+	random := app.channel("random", "U1")
+
+	dm, _, err := app.api.PostMessage("U1", slack.MsgOptionText("hi", false))
+	require.NoError(t, err)
+
+	user := app.openModal(t)
+
+	input, ok := blocks(user.page.page).inputByLabel("Where")
+	require.True(t, ok)
+
+	filter := app.client.multiSelectFilter(input.Element.(*slack.MultiSelectBlockElement))
+	require.NotNil(t, filter)
+	assert.Equal(t, []string{"public"}, filter.Include)
+	assert.True(t, filter.ExcludeBotUsers)
+	assert.Error(t, app.client.selectableConversation(dm, false, filter))
+
+	user.SelectConversations(t, "Where", []string{general, random})
+
+	values := app.submit(t, user)
+	assert.Equal(t, []string{general, random}, values["where_block"]["where"].SelectedConversations)
+}
+
+func TestSelectConversationAndChannels(t *testing.T) {
+	conversation := slack.NewOptionsSelectBlockElement(slack.OptTypeConversations, plainText("Where"), "conversation")
+	conversation.Filter = &slack.SelectBlockElementFilter{Include: []string{"public", "private"}}
+
+	app := newModalApp(t, testModal(
+		slack.NewInputBlock("conversation_block", plainText("Conversation"), nil, conversation),
+		slack.NewInputBlock("channel_block", plainText("Channel"), nil, slack.NewOptionsSelectBlockElement(slack.OptTypeChannels, nil, "channel")),
+		slack.NewInputBlock("channels_block", plainText("Channels"), nil, slack.NewOptionsMultiSelectBlockElement(slack.MultiOptTypeChannels, nil, "channels")),
+	), nil)
+
+	general := app.channel("general", "U1")
+	random := app.channel("random", "U1")
+
+	user := app.openModal(t)
+
+	user.SelectConversation(t, "Conversation", general)
+	user.SelectChannel(t, "Channel", random)
+	user.SelectChannels(t, "Channels", []string{general, random})
+
+	values := app.submit(t, user)
+	assert.Equal(t, general, values["conversation_block"]["conversation"].SelectedConversation)
+	assert.Equal(t, random, values["channel_block"]["channel"].SelectedChannel)
+	assert.Equal(t, []string{general, random}, values["channels_block"]["channels"].SelectedChannels)
+}
+
+func TestClickByTextSkipsOptions(t *testing.T) {
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId,
+				slack.NewSectionBlock(plainText("Report"), nil, slack.NewAccessory(slack.NewOverflowBlockElement("more", testOption("Delete", "delete")))),
+				slack.NewActionBlock("actions",
+					slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, plainText("Action"), "action", testOption("Delete", "delete")),
+					slack.NewButtonBlockElement("delete", "report", plainText("Delete")),
+				),
+			)
+		}
+	})
+
+	user := app.client.User(t, "U1")
+	user.HomeOpen(t)
+
+	_, ok := user.SearchByText(t, "Delete").(*slack.OverflowBlockElement)
+	assert.True(t, ok)
+
+	user.ClickByText(t, "Delete", false)
+
+	click := app.waitInteraction(t, slack.InteractionTypeBlockActions)
+	assert.Equal(t, "delete", click.ActionCallback.BlockActions[0].ActionID)
+	assert.Equal(t, "actions", click.ActionCallback.BlockActions[0].BlockID)
+}
+
+func TestSearchByText(t *testing.T) {
+	image := slack.NewImageBlock("https://example.com/cat.png", "A cat", "", plainText("Cat"))
+	context := slack.NewContextBlock("", plainText("Updated today"), slack.NewImageBlockElement("https://example.com/icon.png", "Icon"))
+	section := slack.NewSectionBlock(plainText("Summary"), []*slack.TextBlockObject{plainText("Status"), plainText("Open")}, nil)
+
+	input := slack.NewInputBlock("name", plainText("Name"), plainText("Your full name"), slack.NewPlainTextInputBlockElement(plainText("Jane Doe"), "name"))
+
+	static := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, plainText("Fruit"), "fruit", testOption("Apple", "apple"))
+	static.OptionGroups = []*slack.OptionGroupBlockObject{slack.NewOptionGroupBlockElement(plainText("Citrus"), testOption("Lemon", "lemon"))}
+	multi := slack.NewOptionsMultiSelectBlockElement(slack.MultiOptTypeStatic, plainText("Fruits"), "fruits", testOption("Pear", "pear"))
+	overflow := slack.NewOverflowBlockElement("more", testOption("Archive", "archive"))
+
+	b := blocks(slack.Blocks{BlockSet: []slack.Block{
+		image,
+		context,
+		section,
+		input,
+		slack.NewActionBlock("actions", static, multi),
+		slack.NewSectionBlock(plainText("More"), nil, slack.NewAccessory(overflow)),
+	}})
+
+	assert.Equal(t, image, b.SearchByText("A cat"))
+	assert.Equal(t, image, b.SearchByText("Cat"))
+	assert.Equal(t, context, b.SearchByText("Updated today"))
+	assert.Equal(t, context, b.SearchByText("Icon"))
+	assert.Equal(t, section, b.SearchByText("Open"))
+	assert.Equal(t, input, b.SearchByText("Name"))
+	assert.Equal(t, input, b.SearchByText("Your full name"))
+	assert.Equal(t, input, b.SearchByText("Jane Doe"))
+	assert.Equal(t, static, b.SearchByText("Apple"))
+	assert.Equal(t, static, b.SearchByText("Lemon"))
+	assert.Equal(t, multi, b.SearchByText("Pear"))
+	assert.Equal(t, overflow, b.SearchByText("Archive"))
+	assert.Nil(t, b.SearchByText("Missing"))
+}
+
+func TestCheckboxesAndRadioButtons(t *testing.T) {
+	boxes := slack.NewCheckboxGroupsBlockElement("boxes", testOption("Box a", "a"), testOption("Box b", "b"), testOption("Box c", "c"))
+	boxes.InitialOptions = []*slack.OptionBlockObject{testOption("Box c", "c")}
+
+	app := newModalApp(t, testModal(
+		slack.NewInputBlock("boxes_block", plainText("Boxes"), nil, boxes),
+		slack.NewActionBlock("radio_block", slack.NewRadioButtonsBlockElement("radio", testOption("Radio x", "x"), testOption("Radio y", "y"))),
+	), nil)
+	user := app.openModal(t)
+	app.waitInteraction(t, slack.InteractionTypeBlockActions)
+
+	user.Check(t, "Box a")
+	user.Check(t, "Box b")
+	user.Uncheck(t, "Box c")
+	user.ChooseRadio(t, "Radio y")
+
+	action := app.waitInteraction(t, slack.InteractionTypeBlockActions).ActionCallback.BlockActions[0]
+	assert.Equal(t, "radio_block", action.BlockID)
+	assert.Equal(t, "y", action.SelectedOption.Value)
+
+	values := app.submit(t, user)
+	selected := values["boxes_block"]["boxes"].SelectedOptions
+	require.Len(t, selected, 2)
+	assert.Equal(t, "a", selected[0].Value)
+	assert.Equal(t, "b", selected[1].Value)
+	assert.Equal(t, "y", values["radio_block"]["radio"].SelectedOption.Value)
+}
+
+func TestPickers(t *testing.T) {
+	timePicker := slack.NewTimePickerBlockElement("time")
+	timePicker.Placeholder = plainText("Hour")
+
+	app := newModalApp(t, testModal(
+		slack.NewInputBlock("date_block", plainText("Day"), nil, slack.NewDatePickerBlockElement("date")),
+		slack.NewActionBlock("time_block", timePicker),
+		slack.NewInputBlock("datetime_block", plainText("When"), nil, slack.NewDateTimePickerBlockElement("datetime")),
+	), nil)
+	user := app.openModal(t)
+	app.waitInteraction(t, slack.InteractionTypeBlockActions)
+
+	at := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+
+	user.PickDate(t, "Day", at)
+	user.PickTime(t, "Hour", at)
+
+	action := app.waitInteraction(t, slack.InteractionTypeBlockActions).ActionCallback.BlockActions[0]
+	assert.Equal(t, "time_block", action.BlockID)
+	assert.Equal(t, "14:30", action.SelectedTime)
+
+	user.PickDateTime(t, "When", at)
+
+	values := app.submit(t, user)
+	assert.Equal(t, "2024-03-05", values["date_block"]["date"].SelectedDate)
+	assert.Equal(t, "14:30", values["time_block"]["time"].SelectedTime)
+	assert.Equal(t, at.Unix(), values["datetime_block"]["datetime"].SelectedDateTime)
+}
+
+func TestChooseOverflowOption(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	menu := slack.NewOverflowBlockElement("menu", testOption("Edit", "edit"), testOption("Remove", "remove"))
+	item := slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "item", false, false), nil, slack.NewAccessory(menu), slack.SectionBlockOptionBlockID("item_block"))
+
+	_, _, err := app.api.PostMessage("U1", slack.MsgOptionBlocks(testSection("list"), item))
+	require.NoError(t, err)
+
+	msg := app.client.User(t, "U1").Messages().Last()
+
+	msg.ChooseOverflowOption(t, "item_block", "Edit")
+	action := app.waitInteraction(t, slack.InteractionTypeBlockActions).ActionCallback.BlockActions[0]
+	assert.Equal(t, "menu", action.ActionID)
+	assert.Equal(t, "item_block", action.BlockID)
+	assert.Equal(t, slack.ActionType("overflow"), action.Type)
+	assert.Equal(t, "edit", action.SelectedOption.Value)
+
+	msg.ChooseOverflowOption(t, "menu", "Remove")
+	action = app.waitInteraction(t, slack.InteractionTypeBlockActions).ActionCallback.BlockActions[0]
+	assert.Equal(t, "remove", action.SelectedOption.Value)
+}
+
+func TestSelectOptionGroupsAndMultiSelect(t *testing.T) {
+	one := slack.NewOptionsGroupSelectBlockElement(slack.OptTypeStatic, plainText("One"), "one",
+		slack.NewOptionGroupBlockElement(plainText("First"), testOption("Option a", "a")),
+		slack.NewOptionGroupBlockElement(plainText("Second"), testOption("Option b", "b")))
+
+	many := slack.NewOptionsGroupMultiSelectBlockElement(slack.MultiOptTypeStatic, plainText("Many"), "many",
+		slack.NewOptionGroupBlockElement(plainText("Letters"), testOption("Option x", "x"), testOption("Option y", "y")),
+		slack.NewOptionGroupBlockElement(plainText("More"), testOption("Option z", "z")))
+	many.WithMaxSelectedItems(2)
+
+	app := newModalApp(t, testModal(
+		slack.NewInputBlock("one_block", plainText("One"), nil, one),
+		slack.NewInputBlock("many_block", plainText("Many"), nil, many),
+	), nil)
+	user := app.openModal(t)
+
+	user.SelectByText(t, "One", "Option b")
+	user.SelectOptionsByText(t, "Many", []string{"Option x", "Option y"})
+	user.SelectOptionsByText(t, "Many", []string{"Option z", "Option x"})
+
+	values := app.submit(t, user)
+	assert.Equal(t, "b", values["one_block"]["one"].SelectedOption.Value)
+
+	selected := values["many_block"]["many"].SelectedOptions
+	require.Len(t, selected, 2)
+	assert.Equal(t, "z", selected[0].Value)
+	assert.Equal(t, "x", selected[1].Value)
+}
+
+func TestDispatchAction(t *testing.T) {
+	query := slack.NewInputBlock("query_block", plainText("Query"), nil, slack.NewPlainTextInputBlockElement(plainText("Search"), "query"))
+	query.DispatchAction = true
+
+	live := slack.NewPlainTextInputBlockElement(nil, "live")
+	live.DispatchActionConfig = &slack.DispatchActionConfig{TriggerActionsOn: []string{"on_character_entered"}}
+	liveBlock := slack.NewInputBlock("live_block", plainText("Live"), nil, live)
+	liveBlock.DispatchAction = true
+
+	app := newModalApp(t, testModal(
+		query,
+		liveBlock,
+		slack.NewInputBlock("other_block", plainText("Other"), nil, slack.NewPlainTextInputBlockElement(nil, "other")),
+	), nil)
+	user := app.openModal(t)
+	app.waitInteraction(t, slack.InteractionTypeBlockActions)
+
+	user.Type(t, "Other", "keep")
+	user.Type(t, "Search", "cats")
+	assert.Len(t, app.interactions, 0, "typing does not press enter")
+
+	user.TypeAndEnter(t, "Search", "dogs")
+	callback := app.waitInteraction(t, slack.InteractionTypeBlockActions)
+	assert.Equal(t, "query", callback.ActionCallback.BlockActions[0].ActionID)
+	assert.Equal(t, "dogs", callback.ActionCallback.BlockActions[0].Value)
+	assert.Equal(t, "keep", callback.View.State.Values["other_block"]["other"].Value)
+
+	user.Type(t, "Live", "x")
+	callback = app.waitInteraction(t, slack.InteractionTypeBlockActions)
+	assert.Equal(t, "live", callback.ActionCallback.BlockActions[0].ActionID)
+
+	user.TypeAndEnter(t, "Other", "still")
+	assert.Len(t, app.interactions, 0, "other input does not dispatch")
+
+	values := app.submit(t, user)
+	assert.Equal(t, "dogs", values["query_block"]["query"].Value)
+	assert.Equal(t, "x", values["live_block"]["live"].Value)
+	assert.Equal(t, "still", values["other_block"]["other"].Value)
+}
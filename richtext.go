@@ -0,0 +1,148 @@
+package slacktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/slack-go/slack"
+	"strings"
+)
+
+// richTextLines returns the text of every section, list item, quote and preformatted
+// element of the rich_text block, the way Slack renders it.
+func richTextLines(elements []slack.RichTextElement) []string {
+	var res []string
+
+	for _, element := range elements {
+		switch x := element.(type) {
+		case *slack.RichTextSection:
+			res = append(res, richTextSectionText(x.Elements))
+		case *slack.RichTextQuote:
+			res = append(res, richTextSectionText(x.Elements))
+		case *slack.RichTextPreformatted:
+			res = append(res, richTextSectionText(x.Elements))
+		case *slack.RichTextList:
+			res = append(res, richTextLines(x.Elements)...)
+		}
+	}
+
+	return res
+}
+
+func richTextSectionText(elements []slack.RichTextSectionElement) string {
+	var b strings.Builder
+
+	for _, element := range elements {
+		switch x := element.(type) {
+		case *slack.RichTextSectionTextElement:
+			b.WriteString(x.Text)
+		case *slack.RichTextSectionLinkElement:
+			if x.Text != "" {
+				b.WriteString(x.Text)
+			} else {
+				b.WriteString(x.URL)
+			}
+		case *slack.RichTextSectionUserElement:
+			b.WriteString("@" + x.UserID)
+		case *slack.RichTextSectionChannelElement:
+			b.WriteString("#" + x.ChannelID)
+		case *slack.RichTextSectionUserGroupElement:
+			b.WriteString("@" + x.UsergroupID)
+		case *slack.RichTextSectionEmojiElement:
+			b.WriteString(":" + x.Name + ":")
+		case *slack.RichTextSectionBroadcastElement:
+			b.WriteString("@" + x.Range)
+		}
+	}
+
+	return b.String()
+}
+
+// richTextContains reports whether the text is a line of the rich_text block or its whole text.
+func richTextContains(block *slack.RichTextBlock, text string) bool {
+	lines := richTextLines(block.Elements)
+
+	for _, line := range lines {
+		if line == text {
+			return true
+		}
+	}
+
+	return strings.Join(lines, "\n") == text
+}
+
+// richTextValue returns the rich_text_value of a rich_text_input with the typed text, one
+// section per paragraph. The state keeps it as JSON in BlockAction.Value, which has no field
+// for it, and richTextPayload moves it to rich_text_value when the payload is sent.
+func richTextValue(text string) string {
+	block := slack.NewRichTextBlock("")
+
+	if text != "" {
+		for _, paragraph := range strings.Split(text, "\n\n") {
+			block.Elements = append(block.Elements, slack.NewRichTextSection(slack.NewRichTextSectionTextElement(paragraph, nil)))
+		}
+	}
+
+	return richTextJSON(block)
+}
+
+func richTextJSON(block *slack.RichTextBlock) string {
+	value := slack.NewRichTextBlock("")
+	if block != nil {
+		value.Elements = block.Elements
+	}
+
+	if value.Elements == nil {
+		value.Elements = []slack.RichTextElement{}
+	}
+
+	b, _ := json.Marshal(value)
+
+	return string(b)
+}
+
+// richTextPayload replaces the value of rich_text_input state entries and actions in the
+// interaction payload with rich_text_value, as Slack sends them.
+func richTextPayload(b []byte) []byte {
+	if !bytes.Contains(b, []byte(slack.METRichTextInput)) {
+		return b
+	}
+
+	var payload interface{}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	if err := d.Decode(&payload); err != nil {
+		return b
+	}
+
+	res, err := json.Marshal(replaceRichTextValues(payload))
+	if err != nil {
+		return b
+	}
+
+	return res
+}
+
+func replaceRichTextValues(payload interface{}) interface{} {
+	switch x := payload.(type) {
+	case map[string]interface{}:
+		if value, ok := x["value"].(string); ok && x["type"] == string(slack.METRichTextInput) {
+			var richText json.RawMessage
+			if err := json.Unmarshal([]byte(value), &richText); err == nil {
+				x["rich_text_value"] = richText
+				delete(x, "value")
+			}
+		}
+
+		for key, item := range x {
+			x[key] = replaceRichTextValues(item)
+		}
+	case []interface{}:
+		for i, item := range x {
+			x[i] = replaceRichTextValues(item)
+		}
+	}
+
+	return payload
+}
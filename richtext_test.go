@@ -0,0 +1,62 @@
+package slacktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchRichText(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	block := slack.NewRichTextBlock("rich",
+		slack.NewRichTextSection(slack.NewRichTextSectionTextElement("Hello ", nil), slack.NewRichTextSectionUserElement("U1", nil)),
+		slack.NewRichTextList(slack.RTEListBullet, 0, slack.NewRichTextSection(slack.NewRichTextSectionTextElement("item one", nil))),
+	)
+
+	_, _, err := app.api.PostMessage("U1", slack.MsgOptionBlocks(block))
+	require.NoError(t, err)
+
+	msg := app.client.User(t, "U1").Messages().Last()
+	msg.SearchByText(t, "Hello @U1")
+	msg.SearchByText(t, "item one")
+}
+
+func TestRichTextInput(t *testing.T) {
+	payloads := make(chan string, 1)
+
+	app := newModalApp(t, testModal(
+		slack.NewInputBlock("notes_block", plainText("Notes"), nil, slack.NewRichTextInputBlockElement(plainText("Write"), "notes")),
+	), func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if kind == "actions" && interaction(t, body).Type == slack.InteractionTypeViewSubmission {
+			values, _ := url.ParseQuery(body)
+			payloads <- values.Get("payload")
+		}
+	})
+	user := app.openModal(t)
+
+	user.Type(t, "Notes", "first line")
+	user.SubmitForm()
+	app.waitInteraction(t, slack.InteractionTypeViewSubmission)
+
+	var payload struct {
+		View struct {
+			State struct {
+				Values map[string]map[string]struct {
+					Type          string          `json:"type"`
+					RichTextValue json.RawMessage `json:"rich_text_value"`
+				} `json:"values"`
+			} `json:"state"`
+		} `json:"view"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(<-payloads), &payload))
+
+	value := payload.View.State.Values["notes_block"]["notes"]
+	assert.Equal(t, "rich_text_input", value.Type)
+	assert.JSONEq(t, `{"type":"rich_text","elements":[{"type":"rich_text_section","elements":[{"type":"text","text":"first line"}]}]}`, string(value.RichTextValue))
+}
@@ -0,0 +1,244 @@
+package slacktest
+
+import (
+	"fmt"
+	"github.com/slack-go/slack"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// values builds view.state.values for every input block. Inputs keep their
+// value from previous when it has one for the same block_id and action_id,
+// otherwise they start from the element's initial value.
+func (b blocks) values(previous map[string]map[string]slack.BlockAction) map[string]map[string]slack.BlockAction {
+	res := map[string]map[string]slack.BlockAction{}
+
+	for _, block := range b.BlockSet {
+		x, ok := block.(*slack.InputBlock)
+		if !ok || x.Element == nil {
+			continue
+		}
+
+		actionId, value, ok := initialValue(x.Element)
+		if !ok {
+			continue
+		}
+
+		if prev, ok := previous[x.BlockID][actionId]; ok {
+			value = prev
+		}
+
+		res[x.BlockID] = map[string]slack.BlockAction{actionId: value}
+	}
+
+	return res
+}
+
+// initialValue returns the action_id of the element and its state value before the user touches it.
+func initialValue(element slack.BlockElement) (string, slack.BlockAction, bool) {
+	switch x := element.(type) {
+	case *slack.PlainTextInputBlockElement:
+		return x.ActionID, slack.BlockAction{
+			Type:  slack.ActionType(x.Type),
+			Value: x.InitialValue,
+		}, true
+	case *slack.RichTextInputBlockElement:
+		return x.ActionID, slack.BlockAction{
+			Type:  slack.ActionType(x.Type),
+			Value: richTextJSON(x.InitialValue),
+		}, true
+	case *slack.NumberInputBlockElement:
+		return x.ActionID, slack.BlockAction{
+			Type:  slack.ActionType(x.Type),
+			Value: x.InitialValue,
+		}, true
+	case *slack.EmailTextInputBlockElement:
+		return x.ActionID, slack.BlockAction{
+			Type:  slack.ActionType(x.Type),
+			Value: x.InitialValue,
+		}, true
+	case *slack.URLTextInputBlockElement:
+		return x.ActionID, slack.BlockAction{
+			Type:  slack.ActionType(x.Type),
+			Value: x.InitialValue,
+		}, true
+	case *slack.SelectBlockElement:
+		value := slack.BlockAction{
+			Type:                 slack.ActionType(x.Type),
+			SelectedUser:         x.InitialUser,
+			SelectedConversation: x.InitialConversation,
+			SelectedChannel:      x.InitialChannel,
+		}
+
+		if x.InitialOption != nil {
+			value.SelectedOption = *x.InitialOption
+		}
+
+		return x.ActionID, value, true
+	case *slack.MultiSelectBlockElement:
+		return x.ActionID, slack.BlockAction{
+			Type:                  slack.ActionType(x.Type),
+			SelectedOptions:       options(x.InitialOptions),
+			SelectedUsers:         x.InitialUsers,
+			SelectedConversations: x.InitialConversations,
+			SelectedChannels:      x.InitialChannels,
+		}, true
+	case *slack.DatePickerBlockElement:
+		return x.ActionID, slack.BlockAction{
+			Type:         slack.ActionType(x.Type),
+			SelectedDate: x.InitialDate,
+		}, true
+	case *slack.TimePickerBlockElement:
+		return x.ActionID, slack.BlockAction{
+			Type:         slack.ActionType(x.Type),
+			SelectedTime: x.InitialTime,
+		}, true
+	case *slack.DateTimePickerBlockElement:
+		return x.ActionID, slack.BlockAction{
+			Type:             slack.ActionType(x.Type),
+			SelectedDateTime: x.InitialDateTime,
+		}, true
+	case *slack.CheckboxGroupsBlockElement:
+		return x.ActionID, slack.BlockAction{
+			Type:            slack.ActionType(x.Type),
+			SelectedOptions: options(x.InitialOptions),
+		}, true
+	case *slack.RadioButtonsBlockElement:
+		value := slack.BlockAction{
+			Type: slack.ActionType(x.Type),
+		}
+
+		if x.InitialOption != nil {
+			value.SelectedOption = *x.InitialOption
+		}
+
+		return x.ActionID, value, true
+	}
+
+	return "", slack.BlockAction{}, false
+}
+
+// validate checks the values of inputs the Slack client validates before submit,
+// returning the error messages by block_id.
+func (b blocks) validate(state map[string]map[string]slack.BlockAction) map[string]string {
+	errors := map[string]string{}
+
+	for _, block := range b.BlockSet {
+		x, ok := block.(*slack.InputBlock)
+		if !ok || x.Element == nil {
+			continue
+		}
+
+		actionId, _, _ := initialValue(x.Element)
+
+		value := state[x.BlockID][actionId].Value
+		if value == "" {
+			continue
+		}
+
+		var message string
+
+		switch el := x.Element.(type) {
+		case *slack.NumberInputBlockElement:
+			message = validateNumber(el, value)
+		case *slack.EmailTextInputBlockElement:
+			if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+				message = "Enter a valid email address."
+			}
+		case *slack.URLTextInputBlockElement:
+			if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+				message = "Enter a valid URL."
+			}
+		}
+
+		if message != "" {
+			errors[x.BlockID] = message
+		}
+	}
+
+	return errors
+}
+
+var numberPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+func validateNumber(el *slack.NumberInputBlockElement, value string) string {
+	if !numberPattern.MatchString(value) {
+		return "Enter a number."
+	}
+
+	if strings.Contains(value, ".") && !el.IsDecimalAllowed {
+		return "Enter a whole number."
+	}
+
+	number, _ := strconv.ParseFloat(value, 64)
+
+	if min, err := strconv.ParseFloat(el.MinValue, 64); err == nil && number < min {
+		return fmt.Sprintf("Enter a number greater than or equal to %s.", el.MinValue)
+	}
+
+	if max, err := strconv.ParseFloat(el.MaxValue, 64); err == nil && number > max {
+		return fmt.Sprintf("Enter a number less than or equal to %s.", el.MaxValue)
+	}
+
+	return ""
+}
+
+func options(list []*slack.OptionBlockObject) []slack.OptionBlockObject {
+	res := []slack.OptionBlockObject{}
+
+	for _, option := range list {
+		if option != nil {
+			res = append(res, *option)
+		}
+	}
+
+	return res
+}
+
+// setValue stores the value of the input element, keeping the values of the other inputs.
+func (p *page) setValue(blockId string, actionId string, value slack.BlockAction) {
+	if p.state == nil {
+		p.state = map[string]map[string]slack.BlockAction{}
+	}
+
+	if p.state[blockId] == nil {
+		p.state[blockId] = map[string]slack.BlockAction{}
+	}
+
+	p.state[blockId][actionId] = value
+}
+
+// value returns the current state value of the element, its initial value if the user did not touch it.
+func (p *page) value(el *element) slack.BlockAction {
+	actionId, value, _ := initialValue(el.element)
+
+	if current, ok := p.state[el.blockId][actionId]; ok {
+		return current
+	}
+
+	return value
+}
+
+// change stores the new value of the element and sends block_actions if the element dispatches them,
+// after the confirm dialog if the element has one.
+func (p *page) change(el *element, value slack.BlockAction) {
+	actionId, _, _ := initialValue(el.element)
+
+	if !el.dispatch {
+		p.setValue(el.blockId, actionId, value)
+		return
+	}
+
+	action := value
+	action.ActionID = actionId
+	action.BlockID = el.blockId
+
+	// A denied confirmation leaves the element as it was.
+	p.guard(elementConfirm(el.element), func() {
+		p.setValue(el.blockId, actionId, value)
+		p.actionCallback(&action, slack.InteractionTypeBlockActions, false, p.state)
+	})
+}
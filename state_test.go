@@ -0,0 +1,72 @@
+package slacktest
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateKeepsEveryInput(t *testing.T) {
+	other := slack.NewPlainTextInputBlockElement(nil, "other")
+	other.InitialValue = "initial"
+
+	option := testOption("A", "a")
+	pick := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, plainText("Pick"), "pick", option)
+	pick.InitialOption = option
+
+	app := newModalApp(t, testModal(
+		slack.NewInputBlock("name_block", plainText("Name"), nil, slack.NewPlainTextInputBlockElement(plainText("Type name"), "name")),
+		slack.NewInputBlock("other_block", plainText("Other"), nil, other),
+		slack.NewInputBlock("pick_block", plainText("Pick"), nil, pick),
+		slack.NewInputBlock("empty_block", plainText("Empty"), nil, slack.NewPlainTextInputBlockElement(nil, "empty")),
+	), nil)
+	user := app.openModal(t)
+
+	user.Type(t, "Type name", "bob")
+
+	values := app.submit(t, user)
+	assert.Equal(t, "bob", values["name_block"]["name"].Value)
+	assert.Equal(t, "plain_text_input", string(values["name_block"]["name"].Type))
+	assert.Equal(t, "initial", values["other_block"]["other"].Value)
+	assert.Equal(t, "a", values["pick_block"]["pick"].SelectedOption.Value)
+	assert.Contains(t, values, "empty_block")
+}
+
+func TestValidateTypedInputs(t *testing.T) {
+	age := slack.NewNumberInputBlockElement(plainText("Age"), "age", false)
+	age.MinValue = "1"
+	age.MaxValue = "120"
+
+	app := newModalApp(t, testModal(
+		slack.NewInputBlock("age_block", plainText("Age"), nil, age),
+		slack.NewInputBlock("mail_block", plainText("Mail"), nil, slack.NewEmailTextInputBlockElement(nil, "mail")),
+		slack.NewInputBlock("site_block", plainText("Site"), nil, slack.NewURLTextInputBlockElement(nil, "site")),
+	), nil)
+	user := app.openModal(t)
+	app.waitInteraction(t, slack.InteractionTypeBlockActions)
+
+	user.Type(t, "Age", "12.5")
+	user.Type(t, "Mail", "nope")
+	user.Type(t, "Site", "example.com")
+	user.SubmitForm()
+
+	user.AssertFieldError(t, "Age", "Enter a whole number.")
+	user.AssertFieldError(t, "Mail", "Enter a valid email address.")
+	user.AssertFieldError(t, "Site", "Enter a valid URL.")
+	assert.Len(t, app.interactions, 0, "invalid forms are not submitted")
+
+	user.Type(t, "Age", "200")
+	user.SubmitForm()
+	user.AssertFieldError(t, "Age", "Enter a number less than or equal to 120.")
+
+	user.Type(t, "Age", "42")
+	user.Type(t, "Mail", "a@b.co")
+	user.Type(t, "Site", "https://example.com")
+
+	values := app.submit(t, user)
+	assert.Equal(t, "42", values["age_block"]["age"].Value)
+	assert.Equal(t, "number_input", string(values["age_block"]["age"].Type))
+	assert.Equal(t, "a@b.co", values["mail_block"]["mail"].Value)
+	assert.Equal(t, "https://example.com", values["site_block"]["site"].Value)
+}
@@ -2,6 +2,7 @@ package slacktest
 
 import (
 	"encoding/json"
+	"fmt"
 	gonanoid "github.com/matoous/go-nanoid"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
@@ -14,101 +15,55 @@ type userClient struct {
 	user         *slack.User
 	userId       string
 	client       *AppHttpClient
-	pageUpdate   <-chan *slack.ModalViewRequest
+	pageUpdate   <-chan *view
+	viewUpdate   chan struct{}
 	currentPage  slack.Blocks
-	currentModal *slack.ModalViewRequest
-	home         *slack.ModalViewRequest
-	viewsStack   []*slack.ModalViewRequest
-	teamId       string
-	messages     *messages
-	_client      *Client
+	currentModal *view
+	home         *view
+	viewsStack   []*view
+	// origin is the message the open modals were opened from, shown again when they are closed.
+	origin  *message
+	teamId  string
+	_client *Client
+	t       *testing.T
 }
 
 func (a *userClient) Messages() Messages {
+	return a.messageViews(a._client.MessagesByUser(a.userId).List)
+}
+
+// EphemeralMessages returns messages posted with chat.postEphemeral, which are visible only to this user.
+func (a *userClient) EphemeralMessages() Messages {
+	ephemeral := a._client.EphemeralMessagesByUser(a.userId)
+	if len(ephemeral.List) == 0 {
+		return nil
+	}
+
+	return a.messageViews(ephemeral.List)
+}
+
+func (a *userClient) messageViews(list []*message) Messages {
 	var messagesWithView []MessageView
 
-	uc := a
+	for _, msg := range list {
+		if msg.isDeleted() {
+			continue
+		}
+
+		msg := msg
 
-	for _, msg := range a.messages.List {
 		messagesWithView = append(messagesWithView, MessageView{
 			slackMessage: msg,
+			user:         a,
 			page: page{
-				state: map[string]map[string]slack.BlockAction{},
+				state: blocks(msg.slackMessage.Blocks).values(nil),
 				page:  msg.slackMessage.Blocks,
-				actionCallback: func(actionId string, typ slack.InteractionType, waitModal bool, state map[string]map[string]slack.BlockAction, value string) {
-					triggerId, _ := gonanoid.Nanoid()
-					a._client.viewsByTrigger[triggerId] = make(chan *slack.ModalViewRequest, 1)
-
-					var view slack.View
-
-					if len(uc.viewsStack) > 0 {
-						view = slack.View{
-							PrivateMetadata: uc.viewsStack[len(uc.viewsStack)-1].PrivateMetadata,
-							State: &slack.ViewState{
-								Values: state,
-							},
-						}
-					}
-
-					event := slack.InteractionCallback{
-						Type:        typ,
-						Token:       "",
-						CallbackID:  "",
-						ResponseURL: "http://localhost" + a._client.port + "/api/response_url/" + msg.slackMessage.Channel + "/" + msg.slackMessage.Timestamp,
-						TriggerID:   triggerId,
-						ActionTs:    "",
-						Team: slack.Team{
-							ID: a.teamId,
-						},
-						Channel:                  slack.Channel{},
-						User:                     *a.user,
-						OriginalMessage:          slack.Message{},
-						Message:                  slack.Message{},
-						Name:                     "",
-						Value:                    "",
-						MessageTs:                "",
-						AttachmentID:             "",
-						ActionCallback:           slack.ActionCallbacks{},
-						View:                     view,
-						ActionID:                 "",
-						APIAppID:                 "",
-						BlockID:                  "",
-						Container:                slack.Container{},
-						DialogSubmissionCallback: slack.DialogSubmissionCallback{},
-						ViewSubmissionCallback:   slack.ViewSubmissionCallback{},
-						ViewClosedCallback:       slack.ViewClosedCallback{},
-						RawState:                 nil,
-					}
-
-					event.ActionCallback.BlockActions = append(event.ActionCallback.BlockActions, &slack.BlockAction{
-						ActionID: actionId,
-						Value:    value,
-					})
-
-					a._client.appClient.SendInteractionAction(&event)
-					if waitModal {
-						view := <-a._client.viewsByTrigger[triggerId]
-						uc.currentModal = view
-						uc.viewsStack = append(uc.viewsStack, view)
-						uc.set(view.Blocks)
-					} else {
-						go func() {
-							view := <-a._client.viewsByTrigger[triggerId]
-							uc.currentModal = view
-							uc.viewsStack = append(uc.viewsStack, view)
-							uc.set(view.Blocks)
-						}()
-					}
-
-					// TODO form maybe error
-					if event.Type == slack.InteractionTypeViewSubmission {
-						uc.viewsStack = uc.viewsStack[:len(uc.viewsStack)-1]
-						if len(uc.viewsStack) == 0 {
-							uc.page.set(uc.home.Blocks)
-						} else {
-							uc.page.set(uc.viewsStack[len(uc.viewsStack)-1].Blocks)
-						}
-					}
+				store: a._client,
+				actionCallback: func(action *slack.BlockAction, typ slack.InteractionType, waitModal bool, state map[string]map[string]slack.BlockAction) {
+					a.messageAction(msg, action, typ, waitModal, state)
+				},
+				optionsCallback: func(action *slack.BlockAction, state map[string]map[string]slack.BlockAction) ([]*slack.OptionBlockObject, error) {
+					return a.messageOptions(msg, action, state)
 				},
 			},
 		})
@@ -117,9 +72,569 @@ func (a *userClient) Messages() Messages {
 	return messagesWithView
 }
 
+// topView returns the view the user is looking at: the open modal or the home tab.
+func (a *userClient) topView() *view {
+	if len(a.viewsStack) > 0 {
+		return a.viewsStack[len(a.viewsStack)-1]
+	}
+
+	return a.home
+}
+
+// viewAction sends an interaction from the home tab or the open modal and shows the modal the app opens.
+func (a *userClient) viewAction(action *slack.BlockAction, typ slack.InteractionType, waitModal bool, state map[string]map[string]slack.BlockAction) {
+	// Without modals the page shows the message they were opened from.
+	if a.origin != nil && len(a.viewsStack) == 0 && typ == slack.InteractionTypeBlockActions {
+		a.messageAction(a.origin, action, typ, waitModal, state)
+		return
+	}
+
+	triggerId := a.newTriggerId()
+
+	event := slack.InteractionCallback{
+		Type:      typ,
+		TriggerID: triggerId,
+		Team: slack.Team{
+			ID: a.teamId,
+		},
+		User: *a.user,
+	}
+
+	if current := a.topView(); current != nil {
+		event.View = current.payload(a.teamId, state)
+
+		if typ == slack.InteractionTypeBlockActions {
+			event.Container = slack.Container{
+				Type:   "view",
+				ViewID: current.ID,
+			}
+		}
+	}
+
+	a.addAction(&event, action)
+
+	resp, err := a.client.SendInteractionAction(&event)
+	if err != nil {
+		a.t.Fatal(err)
+		return
+	}
+
+	a.waitModal(a.t, triggerId, waitModal, nil)
+
+	if typ == slack.InteractionTypeViewSubmission {
+		a.submitted(resp)
+	}
+}
+
+// messageAction sends an interaction from a message and shows the modal the app opens.
+func (a *userClient) messageAction(msg *message, action *slack.BlockAction, typ slack.InteractionType, waitModal bool, state map[string]map[string]slack.BlockAction) {
+	// Forms are submitted from the modal the message opened, not from the message itself.
+	if typ != slack.InteractionTypeBlockActions {
+		a.viewAction(action, typ, waitModal, a.state)
+		return
+	}
+
+	slackMessage := msg.slackMessage
+
+	cv := a._client.conversation(slackMessage.Channel)
+	if cv == nil {
+		a.t.Fatalf("cannot find conversation %s", slackMessage.Channel)
+		return
+	}
+
+	triggerId := a.newTriggerId()
+
+	event := slack.InteractionCallback{
+		Type:        typ,
+		ResponseURL: "http://localhost" + a._client.port + "/api/response_url/" + slackMessage.Channel + "/" + slackMessage.Timestamp,
+		TriggerID:   triggerId,
+		Team: slack.Team{
+			ID: a.teamId,
+		},
+		Channel: slack.Channel{
+			GroupConversation: slack.GroupConversation{
+				Conversation: slack.Conversation{ID: cv.channel.ID},
+				Name:         cv.name(),
+			},
+		},
+		User: *a.user,
+		Container: slack.Container{
+			Type:        "message",
+			MessageTs:   slackMessage.Timestamp,
+			ThreadTs:    slackMessage.ThreadTimestamp,
+			ChannelID:   cv.channel.ID,
+			IsEphemeral: msg.ephemeral,
+		},
+		BlockActionState: &slack.BlockActionStates{
+			Values: state,
+		},
+	}
+
+	// Slack does not send the content of ephemeral messages back to the app.
+	if !msg.ephemeral {
+		a._client.mu.Lock()
+		event.Message = cv.slackMessage(msg)
+		a._client.mu.Unlock()
+	}
+
+	a.addAction(&event, action)
+
+	if _, err := a.client.SendInteractionAction(&event); err != nil {
+		a.t.Fatal(err)
+		return
+	}
+
+	a.waitModal(a.t, triggerId, waitModal, msg)
+}
+
+// viewOptions requests options of an external select in the home tab or the open modal.
+func (a *userClient) viewOptions(action *slack.BlockAction, state map[string]map[string]slack.BlockAction) ([]*slack.OptionBlockObject, error) {
+	if a.origin != nil && len(a.viewsStack) == 0 {
+		return a.messageOptions(a.origin, action, state)
+	}
+
+	event := slack.InteractionCallback{
+		Type: slack.InteractionTypeBlockSuggestion,
+		Team: slack.Team{
+			ID: a.teamId,
+		},
+		User:     *a.user,
+		ActionID: action.ActionID,
+		BlockID:  action.BlockID,
+		Value:    action.Value,
+	}
+
+	if current := a.topView(); current != nil {
+		event.View = current.payload(a.teamId, state)
+		event.Container = slack.Container{
+			Type:   "view",
+			ViewID: current.ID,
+		}
+	}
+
+	return a.client.LoadOptions(&event)
+}
+
+// messageOptions requests options of an external select in a message.
+func (a *userClient) messageOptions(msg *message, action *slack.BlockAction, state map[string]map[string]slack.BlockAction) ([]*slack.OptionBlockObject, error) {
+	slackMessage := msg.slackMessage
+
+	event := slack.InteractionCallback{
+		Type: slack.InteractionTypeBlockSuggestion,
+		Team: slack.Team{
+			ID: a.teamId,
+		},
+		User:     *a.user,
+		ActionID: action.ActionID,
+		BlockID:  action.BlockID,
+		Value:    action.Value,
+		Channel: slack.Channel{
+			GroupConversation: slack.GroupConversation{
+				Conversation: slack.Conversation{ID: slackMessage.Channel},
+			},
+		},
+		Container: slack.Container{
+			Type:        "message",
+			MessageTs:   slackMessage.Timestamp,
+			ThreadTs:    slackMessage.ThreadTimestamp,
+			ChannelID:   slackMessage.Channel,
+			IsEphemeral: msg.ephemeral,
+		},
+		BlockActionState: &slack.BlockActionStates{
+			Values: state,
+		},
+	}
+
+	return a.client.LoadOptions(&event)
+}
+
+func (a *userClient) addAction(event *slack.InteractionCallback, action *slack.BlockAction) {
+	if action == nil {
+		return
+	}
+
+	action.ActionTs = a._client.newTimestamp()
+	event.ActionTs = action.ActionTs
+	event.ActionCallback.BlockActions = append(event.ActionCallback.BlockActions, action)
+}
+
+// submitted applies the app's response_action to the modal stack after a view submission.
+func (a *userClient) submitted(resp *slack.ViewSubmissionResponse) {
+	if len(a.viewsStack) == 0 {
+		return
+	}
+
+	if resp == nil {
+		a.closeViews(1)
+		return
+	}
+
+	if (resp.ResponseAction == slack.RAUpdate || resp.ResponseAction == slack.RAPush) && resp.View == nil {
+		a.t.Fatalf("response_action %s without view", resp.ResponseAction)
+		return
+	}
+
+	switch resp.ResponseAction {
+	case slack.RAUpdate:
+		updated, _ := a._client.updateView(a.viewsStack[len(a.viewsStack)-1], *resp.View)
+		a.viewsStack[len(a.viewsStack)-1] = updated
+		a.currentModal = updated
+		a.refresh(updated.Blocks)
+	case slack.RAPush:
+		if len(a.viewsStack) >= maxViewsStack {
+			a.t.Fatal("push_limit_reached")
+			return
+		}
+
+		a.pushView(a._client.addView(*resp.View))
+	case slack.RAClear:
+		a.closeViews(len(a.viewsStack))
+	case slack.RAErrors:
+		for blockId := range resp.Errors {
+			if _, ok := blocks(a.page.page).inputByBlockId(blockId); !ok {
+				a.t.Fatalf("response_action errors references unknown input block %s", blockId)
+				return
+			}
+		}
+
+		a.errors = resp.Errors
+	default:
+		a.t.Fatalf("unknown response_action %s", resp.ResponseAction)
+	}
+}
+
+// CloseModal clicks the close button of the open modal. With clear_on_close the
+// whole stack is closed. The app gets a view_closed interaction if the modal
+// was opened with notify_on_close.
+func (a *userClient) CloseModal(t *testing.T) {
+	if len(a.viewsStack) == 0 {
+		t.Fatal("no modal is open")
+		return
+	}
+
+	current := a.viewsStack[len(a.viewsStack)-1]
+	state := a.state
+
+	count := 1
+	if current.ClearOnClose {
+		count = len(a.viewsStack)
+	}
+
+	a.closeViews(count)
+
+	if !current.NotifyOnClose {
+		return
+	}
+
+	event := slack.InteractionCallback{
+		Type: slack.InteractionTypeViewClosed,
+		Team: slack.Team{
+			ID: a.teamId,
+		},
+		User: *a.user,
+		View: current.payload(a.teamId, state),
+		ViewClosedCallback: slack.ViewClosedCallback{
+			IsCleared: current.ClearOnClose,
+		},
+	}
+
+	if _, err := a.client.SendInteractionAction(&event); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// closeViews pops count modals and shows the view below them: the previous
+// modal, the message the modals were opened from or the home tab. Without
+// them the page is left empty.
+func (a *userClient) closeViews(count int) {
+	a.viewsStack = a.viewsStack[:len(a.viewsStack)-count]
+
+	if len(a.viewsStack) == 0 {
+		a.currentModal = nil
+
+		if a.origin != nil && a.origin.isDeleted() {
+			a.origin = nil
+		}
+
+		switch {
+		case a.origin != nil:
+			a.page.set(a.origin.slackMessage.Blocks)
+		case a.home != nil:
+			a.page.set(a.home.Blocks)
+		default:
+			a.page.set(slack.Blocks{})
+		}
+	} else {
+		a.currentModal = a.viewsStack[len(a.viewsStack)-1]
+		a.page.set(a.currentModal.Blocks)
+		a.state = a.currentModal.state
+	}
+}
+
+// pushView shows the modal on top of the stack. The values entered in the
+// modal below are kept until the user gets back to it.
+func (a *userClient) pushView(modal *view) {
+	if top := a.currentModal; top != nil && len(a.viewsStack) > 0 {
+		top.state = a.state
+	}
+
+	a._client.own(modal, a)
+	a.currentModal = modal
+	a.viewsStack = append(a.viewsStack, modal)
+	a.set(modal.Blocks)
+}
+
+// SendMessage posts a message as the user and sends the message event to the app.
+// The channel is a conversation ID; the user's own ID means the DM with the app.
+func (a *userClient) SendMessage(t *testing.T, channel string, text string) *MessageView {
+	cv := a._client.conversation(channel)
+	if cv == nil {
+		t.Fatalf("cannot find conversation %s", channel)
+		return nil
+	}
+
+	msg := a.postMessage(t, cv, text, "")
+	if msg == nil {
+		return nil
+	}
+
+	return a.messageViews([]*message{msg}).Last()
+}
+
+// SlashCommand invokes the command from the user's DM with the app and returns
+// the synchronous response, nil for an empty acknowledgement. Delayed responses
+// sent to response_url land in Messages or EphemeralMessages.
+func (a *userClient) SlashCommand(t *testing.T, command string, text string) *MessageView {
+	cv := a._client.conversation(a.userId)
+	if cv == nil {
+		t.Fatalf("cannot find conversation %s", a.userId)
+		return nil
+	}
+
+	if a.user == nil {
+		t.Fatalf("cannot find user %s", a.userId)
+		return nil
+	}
+
+	triggerId := a.newTriggerId()
+
+	resp, err := a.client.SendCommand(&slack.SlashCommand{
+		TeamID:      a.teamId,
+		ChannelID:   cv.channel.ID,
+		ChannelName: cv.name(),
+		UserID:      a.userId,
+		UserName:    a.user.Name,
+		Command:     command,
+		Text:        text,
+		ResponseURL: "http://localhost" + a._client.port + "/api/commands/response_url/" + cv.channel.ID + "/" + a.userId,
+		TriggerID:   triggerId,
+	})
+	if err != nil {
+		t.Fatal(err)
+		return nil
+	}
+
+	a.waitModal(t, triggerId, false, nil)
+
+	if resp == nil {
+		return nil
+	}
+
+	return a.messageViews([]*message{a._client.postCommandResponse(cv, a.userId, resp)}).Last()
+}
+
+// newTriggerId registers a trigger_id the app can open a modal with.
+func (a *userClient) newTriggerId() string {
+	return a._client.newTrigger(a)
+}
+
+// waitModal shows the modal opened with the trigger_id, in background unless wait is set.
+// origin is the message the modal is opened from, nil for the home tab, modals and commands.
+func (a *userClient) waitModal(t *testing.T, triggerId string, wait bool, origin *message) {
+	views := a._client.trigger(triggerId).views
+
+	show := func(modal *view) {
+		if len(a.viewsStack) == 0 {
+			a.origin = origin
+		}
+
+		a.pushView(modal)
+	}
+
+	if !wait {
+		go func() {
+			show(<-views)
+		}()
+		return
+	}
+
+	select {
+	case modal := <-views:
+		show(modal)
+	case <-time.After(time.Second * 5):
+		t.Fatal("wait modal after 5 seconds")
+	}
+}
+
+// GlobalShortcut triggers the global shortcut and waits for the modal it opens.
+func (a *userClient) GlobalShortcut(t *testing.T, callbackId string) {
+	triggerId := a.newTriggerId()
+
+	event := slack.InteractionCallback{
+		Type:       slack.InteractionTypeShortcut,
+		CallbackID: callbackId,
+		TriggerID:  triggerId,
+		ActionTs:   a._client.newTimestamp(),
+		Team: slack.Team{
+			ID: a.teamId,
+		},
+		User: *a.user,
+	}
+
+	if _, err := a.client.SendInteractionAction(&event); err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	a.waitModal(t, triggerId, true, nil)
+}
+
+// Mention posts "<@bot> text" as the user and sends the app_mention event to the app.
+func (a *userClient) Mention(t *testing.T, channel string, text string) *MessageView {
+	cv := a._client.conversation(channel)
+	if cv == nil {
+		t.Fatalf("cannot find conversation %s", channel)
+		return nil
+	}
+
+	msg := a.writeMessage(t, cv, fmt.Sprintf("<@%s> %s", a._client.botUserId, text), "")
+	if msg == nil {
+		return nil
+	}
+
+	a.pushEvent(t, slackevents.AppMentionEvent{
+		Type:            string(slackevents.AppMention),
+		User:            a.userId,
+		Text:            msg.slackMessage.Text,
+		TimeStamp:       msg.slackMessage.Timestamp,
+		ThreadTimeStamp: msg.slackMessage.ThreadTimestamp,
+		Channel:         cv.channel.ID,
+		EventTimeStamp:  msg.slackMessage.Timestamp,
+	})
+
+	return a.messageViews([]*message{msg}).Last()
+}
+
+// writeMessage stores a message written by the user.
+func (a *userClient) writeMessage(t *testing.T, cv *conversation, text string, threadTs string) *message {
+	if !cv.isMember(a.userId) {
+		t.Fatalf("user %s is not in channel %s", a.userId, cv.channel.ID)
+		return nil
+	}
+
+	return a._client.postMessage(cv, &slack.Msg{
+		Type:            "message",
+		User:            a.userId,
+		Text:            text,
+		ThreadTimestamp: threadTs,
+		Team:            a.teamId,
+	})
+}
+
+// postMessage stores a message written by the user and sends the message event to the app.
+func (a *userClient) postMessage(t *testing.T, cv *conversation, text string, threadTs string) *message {
+	msg := a.writeMessage(t, cv, text, threadTs)
+	if msg == nil {
+		return nil
+	}
+
+	a.pushEvent(t, slackevents.MessageEvent{
+		ClientMsgID:     gonanoid.MustID(21),
+		Type:            "message",
+		User:            a.userId,
+		Text:            text,
+		ThreadTimeStamp: msg.slackMessage.ThreadTimestamp,
+		TimeStamp:       msg.slackMessage.Timestamp,
+		Channel:         cv.channel.ID,
+		ChannelType:     cv.channelType(),
+		EventTimeStamp:  msg.slackMessage.Timestamp,
+	})
+
+	return msg
+}
+
+// pushEvent wraps the inner event into an event_callback and sends it to the app.
+func (a *userClient) pushEvent(t *testing.T, innerEvent interface{}) {
+	innerEventBytes, err := json.Marshal(innerEvent)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	rawInnerEvent := json.RawMessage(innerEventBytes)
+	eventId, _ := gonanoid.Nanoid()
+
+	event := &slackevents.EventsAPICallbackEvent{
+		Type:       slackevents.CallbackEvent,
+		InnerEvent: &rawInnerEvent,
+		TeamID:     a.teamId,
+		EventID:    "Ev" + eventId,
+		EventTime:  int(time.Now().Unix()),
+	}
+
+	if err := a.client.PushEvent(event); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// notifyViewUpdate tells the user that a view it shows was changed with views.update.
+func (a *userClient) notifyViewUpdate() {
+	select {
+	case a.viewUpdate <- struct{}{}:
+	default:
+	}
+}
+
+// WaitViewUpdate waits for views.update of the open modal or the home tab and shows
+// the latest version, keeping the values of inputs that did not change.
+func (a *userClient) WaitViewUpdate(t *testing.T) {
+	select {
+	case <-a.viewUpdate:
+	case <-time.After(time.Second * 5):
+		t.Fatal("wait view update after 5 seconds")
+		return
+	}
+
+	top := a.topView()
+
+	for i, v := range a.viewsStack {
+		if latest := a._client.latestView(v.ID); latest != nil && latest != v {
+			latest.state = v.state
+			a.viewsStack[i] = latest
+		}
+	}
+
+	if len(a.viewsStack) > 0 {
+		a.currentModal = a.viewsStack[len(a.viewsStack)-1]
+	}
+
+	if a.home != nil {
+		if latest := a._client.latestView(a.home.ID); latest != nil {
+			a.home = latest
+		}
+	}
+
+	// The home tab is not repainted over the message the closed modals were opened from.
+	if current := a.topView(); current != nil && current != top && (len(a.viewsStack) > 0 || a.origin == nil) {
+		a.refresh(current.Blocks)
+	}
+}
+
 func (a *userClient) WaitHomeUpdate() {
 	v := <-a.pageUpdate
+	a._client.own(v, a)
 	a.home = v
+	a.origin = nil
 	a.page.set(v.Blocks)
 }
 
@@ -129,7 +644,7 @@ func (a *userClient) Wait(duration time.Duration) {
 
 func (a *userClient) HomeOpen(t *testing.T) Page {
 	innerEventBytes, err := json.Marshal(slackevents.AppHomeOpenedEvent{
-		Type:           slackevents.AppHomeOpened,
+		Type:           string(slackevents.AppHomeOpened),
 		User:           a.userId,
 		Channel:        "",
 		EventTimeStamp: "",
@@ -162,7 +677,9 @@ func (a *userClient) HomeOpen(t *testing.T) Page {
 		t.Fatal(err)
 		return nil
 	case v := <-a.pageUpdate:
+		a._client.own(v, a)
 		a.home = v
+		a.origin = nil
 		a.currentPage = v.Blocks
 		a.page.set(v.Blocks)
 	case <-time.After(time.Second * 5):
@@ -0,0 +1,345 @@
+package slacktest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nameModal(callbackId string) slack.ModalViewRequest {
+	modal := testModal(slack.NewInputBlock("name_block", slack.NewTextBlockObject(slack.PlainTextType, "Name", false, false), nil,
+		slack.NewPlainTextInputBlockElement(slack.NewTextBlockObject(slack.PlainTextType, "Type name", false, false), "name")))
+	modal.CallbackID = callbackId
+
+	return modal
+}
+
+func TestSubmitResponseActions(t *testing.T) {
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId, testButton("Open"))
+			return
+		}
+
+		if kind != "actions" {
+			return
+		}
+
+		callback := interaction(t, body)
+
+		switch callback.Type {
+		case slack.InteractionTypeBlockActions:
+			if _, err := app.api.OpenView(callback.TriggerID, nameModal("first")); err != nil {
+				t.Error(err)
+			}
+		case slack.InteractionTypeViewSubmission:
+			switch callback.View.State.Values["name_block"]["name"].Value {
+			case "bad":
+				writeResponse(w, slack.NewErrorsViewSubmissionResponse(map[string]string{"name_block": "Too bad"}))
+			case "update":
+				updated := nameModal("updated")
+				writeResponse(w, slack.NewUpdateViewSubmissionResponse(&updated))
+			case "push":
+				pushed := nameModal("pushed")
+				writeResponse(w, slack.NewPushViewSubmissionResponse(&pushed))
+			case "clear":
+				writeResponse(w, slack.NewClearViewSubmissionResponse())
+			}
+		}
+	})
+
+	user := app.client.User(t, "U1").(*userClient)
+	user.HomeOpen(t)
+	user.ClickByText(t, "Open", true)
+
+	user.Type(t, "Name", "bad")
+	user.SubmitForm()
+	user.AssertFieldError(t, "Name", "Too bad")
+	require.Len(t, user.viewsStack, 1)
+
+	user.Type(t, "Name", "update")
+	user.SubmitForm()
+	require.Len(t, user.viewsStack, 1)
+	assert.Equal(t, "updated", user.topView().CallbackID)
+	assert.Empty(t, user.FormErrors())
+
+	user.Type(t, "Name", "push")
+	user.SubmitForm()
+	require.Len(t, user.viewsStack, 2)
+	assert.Equal(t, "pushed", user.topView().CallbackID)
+
+	user.Type(t, "Name", "closes")
+	user.SubmitForm()
+	require.Len(t, user.viewsStack, 1)
+
+	user.Type(t, "Name", "clear")
+	user.SubmitForm()
+	assert.Len(t, user.viewsStack, 0)
+	user.SearchByText(t, "Open")
+}
+
+func TestCloseModalShowsOriginMessage(t *testing.T) {
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId, testSection("Home"))
+			return
+		}
+
+		if kind != "actions" {
+			return
+		}
+
+		if callback := interaction(t, body); callback.Type == slack.InteractionTypeBlockActions && callback.ActionCallback.BlockActions[0].ActionID == "Open" {
+			if _, err := app.api.OpenView(callback.TriggerID, nameModal("first")); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	channel, ts, err := app.api.PostMessage("U1", slack.MsgOptionBlocks(testButton("Open"), testButton("Again")))
+	require.NoError(t, err)
+
+	user := app.client.User(t, "U1")
+	user.HomeOpen(t)
+	user.Messages().Last().ClickByText(t, "Open", true)
+	app.waitInteraction(t, slack.InteractionTypeBlockActions)
+
+	user.CloseModal(t)
+	user.SearchByText(t, "Again")
+
+	user.ClickByText(t, "Again", false)
+	click := app.waitInteraction(t, slack.InteractionTypeBlockActions)
+	assert.Equal(t, "message", click.Container.Type)
+	assert.Equal(t, ts, click.Container.MessageTs)
+	assert.Equal(t, channel, click.Channel.ID)
+}
+
+func TestCloseModalNotifyOnClose(t *testing.T) {
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId, testButton("Open"))
+			return
+		}
+
+		if kind != "actions" {
+			return
+		}
+
+		callback := interaction(t, body)
+
+		switch callback.Type {
+		case slack.InteractionTypeBlockActions:
+			modal := nameModal("first")
+			modal.NotifyOnClose = true
+
+			if _, err := app.api.OpenView(callback.TriggerID, modal); err != nil {
+				t.Error(err)
+			}
+		case slack.InteractionTypeViewSubmission:
+			pushed := nameModal("second")
+			pushed.NotifyOnClose = true
+			pushed.ClearOnClose = callback.View.State.Values["name_block"]["name"].Value == "clear"
+
+			writeResponse(w, slack.NewPushViewSubmissionResponse(&pushed))
+		}
+	})
+
+	user := app.client.User(t, "U1").(*userClient)
+	user.HomeOpen(t)
+	user.ClickByText(t, "Open", true)
+
+	user.Type(t, "Name", "pop")
+	user.SubmitForm()
+	user.CloseModal(t)
+
+	closed := app.waitInteraction(t, slack.InteractionTypeViewClosed)
+	assert.Equal(t, "second", closed.View.CallbackID)
+	assert.False(t, closed.IsCleared)
+	require.Len(t, user.viewsStack, 1)
+
+	user.Type(t, "Name", "clear")
+	user.SubmitForm()
+	user.CloseModal(t)
+
+	closed = app.waitInteraction(t, slack.InteractionTypeViewClosed)
+	assert.True(t, closed.IsCleared)
+	assert.Len(t, user.viewsStack, 0)
+	user.SearchByText(t, "Open")
+}
+
+func TestCloseModalWithoutHome(t *testing.T) {
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if kind == "actions" {
+			if _, err := app.api.OpenView(interaction(t, body).TriggerID, nameModal("shortcut")); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	user := app.client.User(t, "U1").(*userClient)
+	user.GlobalShortcut(t, "create")
+	user.Type(t, "Name", "draft")
+	user.CloseModal(t)
+
+	assert.Empty(t, user.page.page.BlockSet)
+	assert.Empty(t, user.state)
+	assert.Nil(t, user.ConfirmationDialog())
+}
+
+func TestSendMessage(t *testing.T) {
+	app := newTestApp(t, nil)
+	channel := app.channel("general", "U1")
+	user := app.client.User(t, "U1")
+
+	user.SendMessage(t, "U1", "hello bot")
+
+	event, ok := app.waitEvent(t).(*slackevents.MessageEvent)
+	require.True(t, ok)
+	assert.Equal(t, "hello bot", event.Text)
+	assert.Equal(t, "U1", event.User)
+	assert.Equal(t, "im", event.ChannelType)
+
+	user.SendMessage(t, channel, "hello channel")
+
+	event, ok = app.waitEvent(t).(*slackevents.MessageEvent)
+	require.True(t, ok)
+	assert.Equal(t, channel, event.Channel)
+	assert.Equal(t, "channel", event.ChannelType)
+
+	assert.Len(t, user.Messages(), 2)
+
+	res, err := app.api.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: channel})
+	require.NoError(t, err)
+	require.Len(t, res.Messages, 1)
+	assert.Equal(t, "U1", res.Messages[0].User)
+}
+
+func TestMention(t *testing.T) {
+	app := newTestApp(t, nil)
+	app.client.BotUser("UBOT")
+	channel := app.channel("general", "U1")
+
+	msg := app.client.User(t, "U1").Mention(t, channel, "do X")
+	require.NotNil(t, msg)
+
+	event, ok := app.waitEvent(t).(*slackevents.AppMentionEvent)
+	require.True(t, ok)
+	assert.Equal(t, "<@UBOT> do X", event.Text)
+	assert.Equal(t, "U1", event.User)
+	assert.Equal(t, channel, event.Channel)
+
+	auth, err := app.api.AuthTest()
+	require.NoError(t, err)
+	assert.Equal(t, "UBOT", auth.UserID)
+}
+
+func TestSlashCommand(t *testing.T) {
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if kind == "commands" && strings.Contains(body, "text=sync") {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"response_type":"in_channel","blocks":[{"type":"section","text":{"type":"mrkdwn","text":"public answer"}}]}`))
+		}
+	})
+	user := app.client.User(t, "U1")
+
+	msg := user.SlashCommand(t, "/review", "sync")
+	require.NotNil(t, msg)
+	msg.SearchByText(t, "public answer")
+	assert.Len(t, user.Messages(), 1)
+
+	command := <-app.commands
+	assert.Equal(t, "/review", command.Get("command"))
+	assert.Equal(t, "U1", command.Get("user_id"))
+	assert.Equal(t, "first", command.Get("user_name"))
+
+	assert.Nil(t, user.SlashCommand(t, "/review", "later"))
+
+	command = <-app.commands
+	_, _, err := app.api.PostMessage("",
+		slack.MsgOptionResponseURL(command.Get("response_url"), slack.ResponseTypeEphemeral),
+		slack.MsgOptionBlocks(testSection("delayed answer")))
+	require.NoError(t, err)
+
+	require.Len(t, user.EphemeralMessages(), 1)
+	user.EphemeralMessages().Last().SearchByText(t, "delayed answer")
+	assert.Len(t, user.Messages(), 1)
+}
+
+func TestShortcuts(t *testing.T) {
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if kind != "actions" {
+			return
+		}
+
+		callback := interaction(t, body)
+		if callback.Type != slack.InteractionTypeShortcut && callback.Type != slack.InteractionTypeMessageAction {
+			return
+		}
+
+		text := callback.CallbackID + ":" + callback.Message.Text
+		if _, err := app.api.OpenView(callback.TriggerID, testModal(testSection(text))); err != nil {
+			t.Error(err)
+		}
+	})
+	user := app.client.User(t, "U1")
+
+	user.GlobalShortcut(t, "global")
+	user.SearchByText(t, "global:")
+
+	callback := app.waitInteraction(t, slack.InteractionTypeShortcut)
+	assert.Equal(t, "U1", callback.User.ID)
+
+	user.CloseModal(t)
+
+	channel, ts, err := app.api.PostMessage("U1", slack.MsgOptionText("message text", false))
+	require.NoError(t, err)
+
+	user.Messages().Last().MessageShortcut(t, "on_message")
+	user.SearchByText(t, "on_message:message text")
+
+	callback = app.waitInteraction(t, slack.InteractionTypeMessageAction)
+	assert.Equal(t, channel, callback.Channel.ID)
+	assert.Equal(t, ts, callback.Message.Timestamp)
+}
+
+func TestBlockActionsContext(t *testing.T) {
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId, slack.NewActionBlock("home_block",
+				slack.NewButtonBlockElement("press", "home value", slack.NewTextBlockObject(slack.PlainTextType, "Press", false, false))))
+		}
+	})
+	user := app.client.User(t, "U1")
+
+	user.HomeOpen(t)
+	user.ClickByText(t, "Press", false)
+
+	callback := app.waitInteraction(t, slack.InteractionTypeBlockActions)
+	assert.Equal(t, "view", callback.Container.Type)
+	assert.NotEmpty(t, callback.Container.ViewID)
+	assert.Equal(t, callback.Container.ViewID, callback.View.ID)
+	assert.Equal(t, slack.VTHomeTab, callback.View.Type)
+	assert.Equal(t, "home_block", callback.ActionCallback.BlockActions[0].BlockID)
+	assert.Equal(t, "home value", callback.ActionCallback.BlockActions[0].Value)
+
+	channel, ts, err := app.api.PostMessage("U1", slack.MsgOptionBlocks(slack.NewActionBlock("message_block",
+		slack.NewButtonBlockElement("press", "message value", slack.NewTextBlockObject(slack.PlainTextType, "Message", false, false)))))
+	require.NoError(t, err)
+
+	user.Messages().Last().ClickByText(t, "Message", false)
+
+	callback = app.waitInteraction(t, slack.InteractionTypeBlockActions)
+	assert.Equal(t, "message", callback.Container.Type)
+	assert.Equal(t, ts, callback.Container.MessageTs)
+	assert.Equal(t, channel, callback.Container.ChannelID)
+	assert.Equal(t, channel, callback.Channel.ID)
+	assert.Equal(t, ts, callback.Message.Timestamp)
+	assert.Len(t, callback.Message.Blocks.BlockSet, 1)
+	assert.NotEmpty(t, callback.ActionTs)
+	assert.Equal(t, "message value", callback.ActionCallback.BlockActions[0].Value)
+}
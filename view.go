@@ -0,0 +1,207 @@
+package slacktest
+
+import (
+	"fmt"
+	gonanoid "github.com/matoous/go-nanoid"
+	"github.com/slack-go/slack"
+	"time"
+)
+
+// maxViewsStack is the number of modals Slack allows in one stack.
+const maxViewsStack = 3
+
+// view is a modal or home tab published by the app, with the ID and hash Slack assigns to it.
+type view struct {
+	slack.ModalViewRequest
+	ID    string
+	Hash  string
+	owner *userClient
+	// state holds the values entered in the modal while another one is pushed over it.
+	state map[string]map[string]slack.BlockAction
+}
+
+// trigger is a trigger_id sent to the app with an interaction. The app can
+// exchange it once for a modal, which is then shown to the user.
+type trigger struct {
+	user      *userClient
+	views     chan *view
+	exchanged bool
+}
+
+func newView(request slack.ModalViewRequest) *view {
+	return &view{
+		ModalViewRequest: request,
+		ID:               newId("V"),
+		Hash:             newHash(),
+	}
+}
+
+func newHash() string {
+	return fmt.Sprintf("%d.%s", time.Now().Unix(), gonanoid.MustGenerate("0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ", 8))
+}
+
+// updateView replaces the view content, keeping its ID, and returns the new version
+// with the user showing the view. Views are never changed in place: the user keeps
+// the version on screen until it picks the update up in WaitViewUpdate.
+func (c *Client) updateView(v *view, request slack.ModalViewRequest) (*view, *userClient) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if request.ExternalID == "" {
+		request.ExternalID = v.ExternalID
+	}
+
+	updated := &view{
+		ModalViewRequest: request,
+		ID:               v.ID,
+		Hash:             newHash(),
+		owner:            v.owner,
+	}
+
+	c.views[v.ID] = updated
+
+	for userId, home := range c.homes {
+		if home.ID == v.ID {
+			c.homes[userId] = updated
+		}
+	}
+
+	return updated, updated.owner
+}
+
+// latestView returns the current version of the view, nil if it is unknown.
+func (c *Client) latestView(viewId string) *view {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.views[viewId]
+}
+
+// own records the user showing the view, to whom its updates are delivered.
+func (c *Client) own(v *view, user *userClient) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v.owner = user
+}
+
+func (c *Client) addView(request slack.ModalViewRequest) *view {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.addViewLocked(request)
+}
+
+func (c *Client) addViewLocked(request slack.ModalViewRequest) *view {
+	v := newView(request)
+	c.views[v.ID] = v
+
+	return v
+}
+
+// publishHome replaces the user's home tab. The home tab keeps its view ID between publishes.
+func (c *Client) publishHome(userId string, request slack.ModalViewRequest, hash string) (*view, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	home := c.homes[userId]
+	if home != nil && hash != "" && hash != home.Hash {
+		return nil, "hash_conflict"
+	}
+
+	v := c.addViewLocked(request)
+
+	if home != nil {
+		delete(c.views, v.ID)
+		v.ID = home.ID
+		c.views[v.ID] = v
+	}
+
+	c.homes[userId] = v
+
+	return v, ""
+}
+
+// findView looks up a view by view_id or, when it is empty, by external_id.
+func (c *Client) findView(viewId string, externalId string) *view {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if viewId != "" {
+		return c.views[viewId]
+	}
+
+	for _, v := range c.views {
+		if externalId != "" && v.ExternalID == externalId {
+			return v
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) newTrigger(user *userClient) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	triggerId := fmt.Sprintf("%s.%s", c.newTimestampLocked(), gonanoid.MustGenerate(idAlphabet, 16))
+	c.triggers[triggerId] = &trigger{
+		user:  user,
+		views: make(chan *view, 1),
+	}
+
+	return triggerId
+}
+
+func (c *Client) trigger(triggerId string) *trigger {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.triggers[triggerId]
+}
+
+// exchangeTrigger marks the trigger as used, returning the Slack error code if it cannot be.
+func (c *Client) exchangeTrigger(triggerId string) (*trigger, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tr, ok := c.triggers[triggerId]
+	if !ok {
+		return nil, "invalid_trigger_id"
+	}
+
+	if tr.exchanged {
+		return nil, "exchanged_trigger_id"
+	}
+
+	tr.exchanged = true
+
+	return tr, ""
+}
+
+// payload renders the view the way Slack sends it in interactions.
+func (v *view) payload(teamId string, state map[string]map[string]slack.BlockAction) slack.View {
+	if state == nil {
+		state = map[string]map[string]slack.BlockAction{}
+	}
+
+	return slack.View{
+		ID:              v.ID,
+		TeamID:          teamId,
+		Type:            v.Type,
+		Title:           v.Title,
+		Close:           v.Close,
+		Submit:          v.Submit,
+		Blocks:          v.Blocks,
+		PrivateMetadata: v.PrivateMetadata,
+		CallbackID:      v.CallbackID,
+		State: &slack.ViewState{
+			Values: state,
+		},
+		Hash:          v.Hash,
+		ClearOnClose:  v.ClearOnClose,
+		NotifyOnClose: v.NotifyOnClose,
+		ExternalID:    v.ExternalID,
+		BotID:         BotId,
+	}
+}
@@ -0,0 +1,203 @@
+package slacktest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewsUpdateInOpenRequest(t *testing.T) {
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId, testButton("Go"))
+			return
+		}
+
+		if kind != "actions" {
+			return
+		}
+
+		if callback := interaction(t, body); callback.Type == slack.InteractionTypeBlockActions {
+			loading, err := app.api.OpenView(callback.TriggerID, testModal(testSection("Loading")))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if _, err := app.api.UpdateView(testModal(testSection("Loaded")), "", loading.Hash, loading.ID); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	user := app.client.User(t, "U1")
+	user.HomeOpen(t)
+	user.ClickByText(t, "Go", true)
+	user.SearchByText(t, "Loading")
+
+	user.WaitViewUpdate(t)
+	user.SearchByText(t, "Loaded")
+}
+
+func TestViewsUpdateKeepsInputValues(t *testing.T) {
+	name := slack.NewInputBlock("name", slack.NewTextBlockObject(slack.PlainTextType, "Name", false, false), nil,
+		slack.NewPlainTextInputBlockElement(nil, "name"))
+
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId, testButton("Open"))
+			return
+		}
+
+		if kind != "actions" {
+			return
+		}
+
+		callback := interaction(t, body)
+		if callback.Type != slack.InteractionTypeBlockActions {
+			return
+		}
+
+		switch callback.ActionCallback.BlockActions[0].ActionID {
+		case "Open":
+			if _, err := app.api.OpenView(callback.TriggerID, testModal(name, testButton("Check"))); err != nil {
+				t.Error(err)
+			}
+		case "Check":
+			if _, err := app.api.UpdateView(testModal(name, testSection("Name is free")), "", callback.View.Hash, callback.View.ID); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	user := app.client.User(t, "U1")
+	user.HomeOpen(t)
+	user.ClickByText(t, "Open", true)
+	user.Type(t, "Name", "Slackster")
+	user.ClickByText(t, "Check", false)
+
+	user.WaitViewUpdate(t)
+	user.SearchByText(t, "Name is free")
+
+	user.SubmitForm()
+	submission := app.waitInteraction(t, slack.InteractionTypeViewSubmission)
+	assert.Equal(t, "Slackster", submission.View.State.Values["name"]["name"].Value)
+}
+
+func TestViewsUpdateErrors(t *testing.T) {
+	errs := make(chan error, 3)
+
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId, testButton("Open"))
+			return
+		}
+
+		if kind != "actions" {
+			return
+		}
+
+		if callback := interaction(t, body); callback.Type == slack.InteractionTypeBlockActions {
+			modal := testModal(testSection("Modal"))
+			modal.ExternalID = "external"
+
+			if _, err := app.api.OpenView(callback.TriggerID, modal); err != nil {
+				t.Error(err)
+				return
+			}
+
+			_, err := app.api.UpdateView(testModal(testSection("Updated")), "external", "stale", "")
+			errs <- err
+
+			_, err = app.api.UpdateView(testModal(testSection("Updated")), "", "", "V0")
+			errs <- err
+
+			_, err = app.api.OpenView(callback.TriggerID, modal)
+			errs <- err
+		}
+	})
+
+	user := app.client.User(t, "U1")
+	user.HomeOpen(t)
+	user.ClickByText(t, "Open", true)
+
+	assert.EqualError(t, <-errs, "hash_conflict")
+	assert.EqualError(t, <-errs, "not_found")
+	assert.EqualError(t, <-errs, "exchanged_trigger_id")
+}
+
+func TestViewsPush(t *testing.T) {
+	errs := make(chan error, 1)
+
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId, testButton("Open"))
+			return
+		}
+
+		if kind != "actions" {
+			return
+		}
+
+		callback := interaction(t, body)
+		if callback.Type != slack.InteractionTypeBlockActions {
+			return
+		}
+
+		switch callback.ActionCallback.BlockActions[0].ActionID {
+		case "Open":
+			if _, err := app.api.OpenView(callback.TriggerID, testModal(testSection("First"), testButton("Push"))); err != nil {
+				t.Error(err)
+			}
+		case "Push":
+			_, err := app.api.PushView(callback.TriggerID, testModal(testSection("Pushed"), testButton("Push")))
+			errs <- err
+		}
+	})
+
+	user := app.client.User(t, "U1")
+	user.HomeOpen(t)
+	user.ClickByText(t, "Open", true)
+
+	for i := 0; i < 2; i++ {
+		user.ClickByText(t, "Push", true)
+		require.NoError(t, <-errs)
+		user.SearchByText(t, "Pushed")
+	}
+
+	user.ClickByText(t, "Push", false)
+	assert.EqualError(t, <-errs, "push_limit_reached")
+
+	user.CloseModal(t)
+	user.CloseModal(t)
+	user.SearchByText(t, "First")
+}
+
+func TestViewsUpdateHome(t *testing.T) {
+	app := newTestApp(t, func(app *testApp, kind string, body string, w http.ResponseWriter) {
+		if userId, ok := homeOpened(kind, body); ok {
+			app.publishHome(t, userId, testButton("Refresh"))
+			return
+		}
+
+		if kind != "actions" {
+			return
+		}
+
+		if callback := interaction(t, body); callback.Type == slack.InteractionTypeBlockActions {
+			if _, err := app.api.UpdateView(testModal(testSection("Refreshed")), "", callback.View.Hash, callback.View.ID); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	user := app.client.User(t, "U1")
+	user.HomeOpen(t)
+	user.ClickByText(t, "Refresh", false)
+
+	user.WaitViewUpdate(t)
+	user.SearchByText(t, "Refreshed")
+}